@@ -0,0 +1,72 @@
+package template
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// Buffered will make Execute and ExecuteTemplate render into a buffer, and only write the output to the writer if
+// the execution succeeds; so a failing execution does not leave partial output. See ExecuteBuffered.
+func Buffered() anOption {
+	return func(t *Template) error {
+		t.buffered = true
+		return nil
+	}
+}
+
+// maxPooledBuffer is the capacity above which buffers are not put back into the pool; so an occasional large page
+// does not keep its memory around.
+const maxPooledBuffer = 1 << 20
+
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer { return bufferPool.Get().(*bytes.Buffer) }
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// ExecuteBuffered is the same as Execute, but the template is rendered into a buffer first; the output is only
+// written to w if the execution succeeds, otherwise nothing is written and the error is returned as is.
+func (t *Template) ExecuteBuffered(w io.Writer, data interface{}) error {
+	return t.execute(w, true, func(snap *snapshot, w io.Writer) error {
+		return snap.tmpl.Execute(w, data)
+	})
+}
+
+// execute runs fn, which executes the template, on the current set of templates. If buffered is set, or the
+// ErrorOverlay option is in effect, the output is rendered into a buffer first. With the ErrorOverlay option, errors
+// are written to w as an error page.
+func (t *Template) execute(w io.Writer, buffered bool, fn func(snap *snapshot, w io.Writer) error) error {
+	snap, err := t.snapshot()
+	overlay := t.overlaid()
+	// Rather than quietly using the last good set of templates, the overlay shows why the new set failed.
+	if overlay && err == nil {
+		err = t.ReloadError()
+	}
+	if err == nil {
+		if !buffered && !overlay {
+			return fn(snap, w)
+		}
+		buf := getBuffer()
+		defer putBuffer(buf)
+		if err = fn(snap, buf); err == nil {
+			_, err = buf.WriteTo(w)
+			return err
+		}
+		if overlay {
+			err = t.locateExecError(err, snap.files)
+		}
+	}
+	if overlay {
+		t.ErrorPage(w, err)
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package template_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+	texttemplate "text/template"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplateExecuteBuffered(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/index.template": {Data: []byte(`partial {{index . 0}}{{define "page"}}page {{index . 0}}{{end}}`)},
+	}
+
+	tests := []struct {
+		buffered bool
+		execute  func(tpl *template.Template, sb *strings.Builder, data interface{}) error
+	}{
+		{
+			execute: func(tpl *template.Template, sb *strings.Builder, data interface{}) error {
+				return tpl.ExecuteBuffered(sb, data)
+			},
+		},
+		{
+			buffered: true,
+			execute: func(tpl *template.Template, sb *strings.Builder, data interface{}) error {
+				return tpl.ExecuteTemplate(sb, "page", data)
+			},
+		},
+	}
+
+	for i, test := range tests {
+		config := template.BaseConfig(template.ParseFS(fsys, "tpl/index.template"), template.Reload(template.ReloadNever))
+		if test.buffered {
+			config = append(config, template.Buffered())
+		}
+		tpl := template.Must(template.Must(config.NewTemplate("index.template")).ParseFiles())
+
+		var sb strings.Builder
+		err := test.execute(tpl, &sb, []string{})
+		var eerr texttemplate.ExecError
+		if !errors.As(err, &eerr) {
+			t.Errorf("%v: expected the execution error as is, got %T: %v", i, err, err)
+		}
+		if sb.Len() != 0 {
+			t.Errorf("%v: expected nothing to be written, got %q", i, sb.String())
+		}
+
+		sb.Reset()
+		if err := test.execute(tpl, &sb, []string{"ok"}); err != nil {
+			t.Errorf("%v: expected nil error, got %v", i, err)
+		}
+		if got := sb.String(); !strings.HasSuffix(got, " ok") {
+			t.Errorf("%v: expected the output to be written, got %q", i, got)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package template
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"io/fs"
+	"time"
+)
+
+// fileStamp identifies a version of a file, so that we can tell when it has changed.
+type fileStamp struct {
+	name    string
+	modTime time.Time
+	size    int64
+	// sum is the sha1 of the contents of the file. It is only calculated if the file system does not provide
+	// modification times; as is the case for embed.FS.
+	sum string
+	// missing is set if the file could not be stat-ed.
+	missing bool
+}
+
+func (fst fileStamp) equal(other fileStamp) bool {
+	return fst.name == other.name &&
+		fst.modTime.Equal(other.modTime) &&
+		fst.size == other.size &&
+		fst.sum == other.sum &&
+		fst.missing == other.missing
+}
+
+// fileStamps is an ordered list of stamps; as the order of the files matter when parsing.
+type fileStamps []fileStamp
+
+// equal reports whether both lists contain the same files, in the same order, with the same stamps.
+func (fsts fileStamps) equal(other fileStamps) bool {
+	if len(fsts) != len(other) {
+		return false
+	}
+	for i := range fsts {
+		if !fsts[i].equal(other[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func stampFile(fsys fs.FS, name string) fileStamp {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return fileStamp{name: name, missing: true}
+	}
+	stamp := fileStamp{
+		name:    name,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+	}
+	if stamp.modTime.IsZero() {
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			stamp.missing = true
+			return stamp
+		}
+		stamp.sum = fmt.Sprintf("%x", sha1.Sum(b))
+	}
+	return stamp
+}
+
+// stampFiles returns the stamps of the named files, in the order given.
+func stampFiles(fsys fs.FS, filenames ...string) fileStamps {
+	stamps := make(fileStamps, 0, len(filenames))
+	for _, filename := range filenames {
+		stamps = append(stamps, stampFile(fsys, filename))
+	}
+	return stamps
+}
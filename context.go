@@ -0,0 +1,177 @@
+package template
+
+import (
+	"context"
+	"html/template"
+	"io"
+	"text/template/parse"
+)
+
+// ExecuteContext is the same as Execute, but honors the cancellation and deadline of ctx: the execution stops with the
+// error of ctx if it is done before the templates are reloaded, before a build helper builds a file, or when the
+// template writes its output. The context is available to the templates, and so to helpers, with the context helper:
+//
+//	{{fetch context .ID}}
+//
+// The helpers are bound to a copy of the templates, which is made once and reused for later calls; so only the first
+// calls after the templates are reloaded are slower than Execute. Templates that use neither the context helper nor
+// the build helpers need no copy, and are executed as with Execute.
+func (t *Template) ExecuteContext(ctx context.Context, w io.Writer, data interface{}) error {
+	return t.executeContext(ctx, w, "", "", data, t.buffered)
+}
+
+// ExecuteTemplateContext is the same as ExecuteTemplate, but honors ctx as ExecuteContext does.
+func (t *Template) ExecuteTemplateContext(ctx context.Context, w io.Writer, name string, data interface{}) error {
+	return t.executeContext(ctx, w, name, "", data, t.buffered)
+}
+
+// executeContext executes the template with the given name, or the template itself if name is empty, with its
+// helpers bound to ctx. If fragment is set, only the fragment of the template is executed; see ExecuteFragment. See
+// execute for buffered.
+func (t *Template) executeContext(ctx context.Context, w io.Writer, name, fragment string, data interface{}, buffered bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return t.execute(w, buffered, func(snap *snapshot, w io.Writer) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		set := &snap.templateSet
+		if fragment != "" {
+			var err error
+			if set, err = t.fragmentSet(snap, name, fragment); err != nil {
+				return err
+			}
+			name = fragment
+		}
+		w = ctxWriter{ctx: ctx, w: w}
+		if !set.usesContext {
+			// Nothing in the templates can see ctx; so there is no need for a copy with the helpers bound to it.
+			if name == "" {
+				return set.tmpl.Execute(w, data)
+			}
+			return set.tmpl.ExecuteTemplate(w, name, data)
+		}
+		bt, err := t.bind(set, ctx)
+		if err != nil {
+			return err
+		}
+		defer set.release(bt)
+		if name == "" {
+			return bt.tmpl.Execute(w, data)
+		}
+		return bt.tmpl.ExecuteTemplate(w, name, data)
+	})
+}
+
+// boundTemplate is a copy of the templates of a snapshot with the helpers bound to ctx. It is used by one execution
+// at a time, which sets ctx for its duration.
+type boundTemplate struct {
+	tmpl *template.Template
+	ctx  context.Context
+}
+
+func (bt *boundTemplate) context() context.Context { return bt.ctx }
+
+// bind returns a copy of the templates of set with the helpers bound to ctx; the copy is given back with release.
+// Copies are kept with the set, so the templates are only cloned and escaped again once they are reloaded.
+func (t *Template) bind(set *templateSet, ctx context.Context) (*boundTemplate, error) {
+	bt, _ := set.bound.Get().(*boundTemplate)
+	if bt == nil {
+		tmpl, err := set.pristine.Clone()
+		if err != nil {
+			return nil, err
+		}
+		bt = &boundTemplate{tmpl: tmpl}
+		tmpl.Funcs(t.contextHelpers(bt.context))
+	}
+	bt.ctx = ctx
+	return bt, nil
+}
+
+// release gives back a copy of the templates returned by bind.
+func (set *templateSet) release(bt *boundTemplate) {
+	bt.ctx = nil
+	set.bound.Put(bt)
+}
+
+// usesContext reports whether any of the templates of tmpl use a helper that ExecuteContext binds to its context.
+func (t *Template) usesContext(tmpl *template.Template) bool {
+	helpers := t.contextHelpers(nil)
+	for _, tpl := range tmpl.Templates() {
+		if tpl.Tree == nil {
+			continue
+		}
+		uses := false
+		walkIdentifiers(tpl.Tree.Root, func(node *parse.IdentifierNode) {
+			if _, ok := helpers[node.Ident]; ok {
+				uses = true
+			}
+		})
+		if uses {
+			return true
+		}
+	}
+	return false
+}
+
+// contextHelpers returns the helpers bound to the context returned by ctx: the context helper, and the build helpers
+// that have not been replaced by the user.
+func (t *Template) contextHelpers(ctx func() context.Context) template.FuncMap {
+	helpers := template.FuncMap{
+		"context": ctx,
+	}
+	builds := template.FuncMap{
+		"buildMimeTypeFiles": func(mimetype, fnames string) (string, error) {
+			if err := ctx().Err(); err != nil {
+				return "", err
+			}
+			return t.BuildMimeTypeFile(mimetype, fnames)
+		},
+		"buildJSFiles": func(fnames string) (string, error) {
+			if err := ctx().Err(); err != nil {
+				return "", err
+			}
+			return t.BuildJSFile(fnames)
+		},
+		"buildLinkToJSFiles": func(fnames string) (template.HTML, error) {
+			if err := ctx().Err(); err != nil {
+				return "", err
+			}
+			return t.LinkToAndBuildJSFile(fnames)
+		},
+		"buildCSSFiles": func(fnames string) (string, error) {
+			if err := ctx().Err(); err != nil {
+				return "", err
+			}
+			return t.BuildCSSFile(fnames)
+		},
+		"buildLinkToCSSFiles": func(fnames string) (template.HTML, error) {
+			if err := ctx().Err(); err != nil {
+				return "", err
+			}
+			return t.LinkToAndBuildCSSFile(fnames)
+		},
+	}
+	t.parseLock.Lock()
+	defer t.parseLock.Unlock()
+	for name, fn := range builds {
+		if _, ok := t.userHelpers[name]; !ok {
+			helpers[name] = fn
+		}
+	}
+	return helpers
+}
+
+// ctxWriter is a writer that fails with the error of its context once the context is done.
+type ctxWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (cw ctxWriter) Write(p []byte) (int, error) {
+	if err := cw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cw.w.Write(p)
+}
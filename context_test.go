@@ -0,0 +1,151 @@
+package template_test
+
+import (
+	"context"
+	"errors"
+	htmltemplate "html/template"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdey/template"
+)
+
+type ctxKey string
+
+func TestTemplateExecuteContext(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/index.template": {Data: []byte(`a{{value context "user"}}{{stop}}b`)},
+	}
+
+	var stop func()
+	tpl := template.Must(
+		template.Must(
+			template.New("index.template",
+				template.ParseFS(fsys, "tpl/index.template"),
+				template.Reload(template.ReloadOnChange),
+				template.Helpers(htmltemplate.FuncMap{
+					"value": func(ctx context.Context, key string) string {
+						value, _ := ctx.Value(ctxKey(key)).(string)
+						return value
+					},
+					"stop": func() string {
+						if stop != nil {
+							stop()
+						}
+						return ""
+					},
+				}),
+			)).ParseFiles())
+
+	ctx := context.WithValue(context.Background(), ctxKey("user"), "bob")
+	var sb strings.Builder
+	if err := tpl.ExecuteContext(ctx, &sb, nil); err != nil {
+		t.Fatalf("ExecuteContext, expected nil error, got %v", err)
+	}
+	if sb.String() != "abobb" {
+		t.Errorf("ExecuteContext, expected “abobb” got “%v”", sb.String())
+	}
+	// Without a context the context helper returns an empty context.
+	ExecuteTemplateOrFail(t, tpl, nil, "ab")
+
+	// A context that is already done does not execute the template.
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	sb.Reset()
+	if err := tpl.ExecuteContext(cctx, &sb, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if sb.Len() != 0 {
+		t.Errorf("expected nothing to be written, got %q", sb.String())
+	}
+
+	// Canceling the context stops the output.
+	cctx, stop = context.WithCancel(ctx)
+	defer stop()
+	sb.Reset()
+	if err := tpl.ExecuteContext(cctx, &sb, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if sb.String() != "abob" {
+		t.Errorf("expected output to stop after “abob”, got %q", sb.String())
+	}
+}
+
+func TestTemplateExecuteContextHelpers(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/index.template": {Data: []byte(`{{value context "user"}} {{buildJSFiles "app.js"}}`)},
+	}
+
+	tpl := template.Must(
+		template.Must(
+			template.New("index.template",
+				template.ParseFS(fsys, "tpl/index.template"),
+				template.Reload(template.ReloadNever),
+				template.Helpers(htmltemplate.FuncMap{
+					"value": func(ctx context.Context, key string) string {
+						value, _ := ctx.Value(ctxKey(key)).(string)
+						return value
+					},
+					"buildJSFiles": func(fnames string) string { return "mine " + fnames },
+				}),
+			)).ParseFiles())
+
+	// Each execution sees its own context, and the build helper replaced by the user is kept.
+	for _, user := range []string{"bob", "alice", "bob"} {
+		ctx := context.WithValue(context.Background(), ctxKey("user"), user)
+		var sb strings.Builder
+		if err := tpl.ExecuteContext(ctx, &sb, nil); err != nil {
+			t.Fatalf("ExecuteContext, expected nil error, got %v", err)
+		}
+		if expected := user + " mine app.js"; sb.String() != expected {
+			t.Errorf("ExecuteContext, expected “%v” got “%v”", expected, sb.String())
+		}
+	}
+}
+
+func TestTemplateExecuteContextNoHelpers(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/index.template": {Data: []byte(`a{{stop}}b`)},
+	}
+
+	var stop func()
+	tpl := template.Must(
+		template.Must(
+			template.New("index.template",
+				template.ParseFS(fsys, "tpl/index.template"),
+				template.Reload(template.ReloadNever),
+				template.Helpers(htmltemplate.FuncMap{
+					"stop": func() string {
+						if stop != nil {
+							stop()
+						}
+						return ""
+					},
+				}),
+			)).ParseFiles())
+
+	// Templates that do not use the context are executed directly, and still stop once the context is done.
+	var sb strings.Builder
+	if err := tpl.ExecuteContext(context.Background(), &sb, nil); err != nil {
+		t.Fatalf("ExecuteContext, expected nil error, got %v", err)
+	}
+	if sb.String() != "ab" {
+		t.Errorf("ExecuteContext, expected “ab” got “%v”", sb.String())
+	}
+	ExecuteTemplateOrFail(t, tpl, nil, "ab")
+
+	var ctx context.Context
+	ctx, stop = context.WithCancel(context.Background())
+	defer stop()
+	sb.Reset()
+	if err := tpl.ExecuteContext(ctx, &sb, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if sb.String() != "a" {
+		t.Errorf("expected output to stop after “a”, got %q", sb.String())
+	}
+}
@@ -0,0 +1,120 @@
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// StrictDefines will make it an error for more then one file to define a template with the same name. Without this
+// option, as with html/template, the last definition silently replaces the earlier ones. Redefining a template that
+// was defined with the block action is not an error, as that is what blocks are for.
+func StrictDefines() anOption {
+	return func(t *Template) error {
+		t.strictDefines = true
+		return nil
+	}
+}
+
+// Definition is where a template is defined.
+type Definition struct {
+	File string
+	Line int
+	// Block is set if the template is defined by a block action; blocks are meant to be redefined.
+	Block bool
+}
+
+func (d Definition) String() string { return fmt.Sprintf("%v:%v", d.File, d.Line) }
+
+// DuplicateDefinition is a template that is defined more then once.
+type DuplicateDefinition struct {
+	Name string
+	// First is the first definition of the template, and Duplicate the definition that would have replaced it.
+	First     Definition
+	Duplicate Definition
+}
+
+// DuplicateDefineError is the error returned, when using the StrictDefines option, if templates are defined more
+// then once. It lists every duplicate definition.
+type DuplicateDefineError []DuplicateDefinition
+
+func (de DuplicateDefineError) Error() string {
+	dups := make([]string, 0, len(de))
+	for _, dup := range de {
+		dups = append(dups, fmt.Sprintf("“%v” defined at %v and %v", dup.Name, dup.First, dup.Duplicate))
+	}
+	return "template: duplicate definitions: " + strings.Join(dups, "; ")
+}
+
+// definitions tracks where templates are defined, to find duplicate definitions.
+type definitions struct {
+	leftDelim, rightDelim string
+
+	defined    map[string]Definition
+	duplicates DuplicateDefineError
+}
+
+func newDefinitions(leftDelim, rightDelim string) *definitions {
+	return &definitions{
+		leftDelim:  leftDelim,
+		rightDelim: rightDelim,
+		defined:    make(map[string]Definition),
+	}
+}
+
+// add records the templates defined by the text of the file, which is parsed into the template named name. Templates
+// that are empty are not counted as definitions, as they do not replace an existing template.
+func (defs *definitions) add(filename, name, text string) {
+	trees := make(map[string]*parse.Tree)
+	tree := parse.New(name)
+	tree.Mode = parse.SkipFuncCheck
+	if _, err := tree.Parse(text, defs.leftDelim, defs.rightDelim, trees); err != nil {
+		// Parsing the file for real will report the error.
+		return
+	}
+	// Templates defined by a block action are invoked where they are defined, with the name of the block preceded by
+	// the block keyword.
+	blocks := make(map[string]bool)
+	names := make([]string, 0, len(trees))
+	for name, tree := range trees {
+		names = append(names, name)
+		walkTemplateNodes(tree.Root, func(node *parse.TemplateNode) {
+			before := strings.TrimRight(text[:node.Position()], " \t\r\n")
+			if strings.HasSuffix(before, "block") {
+				blocks[node.Name] = true
+			}
+		})
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return trees[names[i]].Root.Position() < trees[names[j]].Root.Position()
+	})
+	for _, name := range names {
+		root := trees[name].Root
+		if parse.IsEmptyTree(root) {
+			continue
+		}
+		def := Definition{
+			File:  filename,
+			Line:  1 + strings.Count(text[:root.Position()], "\n"),
+			Block: blocks[name],
+		}
+		if first, ok := defs.defined[name]; ok && !first.Block {
+			defs.duplicates = append(defs.duplicates, DuplicateDefinition{
+				Name:      name,
+				First:     first,
+				Duplicate: def,
+			})
+			continue
+		}
+		defs.defined[name] = def
+	}
+}
+
+// err returns the duplicate definitions as an error, or nil if there are none.
+func (defs *definitions) err() error {
+	if len(defs.duplicates) == 0 {
+		return nil
+	}
+	return defs.duplicates
+}
@@ -0,0 +1,51 @@
+package template_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplateStrictDefines(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/layout.template": {Data: []byte(`{{block "title" .}}Title{{end}}
+{{template "body" .}}`)},
+		"tpl/page.template": {Data: []byte(`{{define "title"}}Page{{end}}
+{{define "body"}}
+	Body
+{{end}}`)},
+		"tpl/other.template": {Data: []byte(`
+
+{{define "body"}}Other{{end}}`)},
+	}
+
+	_, err := template.Must(
+		template.New("layout.template",
+			template.ParseFS(fsys, "tpl/layout.template", "tpl/page.template", "tpl/other.template"),
+			template.StrictDefines(),
+		)).ParseFiles()
+
+	var dupErr template.DuplicateDefineError
+	if !errors.As(err, &dupErr) {
+		t.Fatalf("expected a DuplicateDefineError got %v", err)
+	}
+	if len(dupErr) != 1 {
+		t.Fatalf("expected one duplicate definition got %v", dupErr)
+	}
+	expected := "template: duplicate definitions: “body” defined at tpl/page.template:2 and tpl/other.template:3"
+	if err.Error() != expected {
+		t.Errorf("expected error “%v” got “%v”", expected, err)
+	}
+
+	// Without the other template, redefining the block is fine.
+	tpl := template.Must(
+		template.Must(
+			template.New("layout.template",
+				template.ParseFS(fsys, "tpl/layout.template", "tpl/page.template"),
+				template.StrictDefines(),
+			)).ParseFiles())
+	ExecuteTemplateOrFail(t, tpl, nil, "Page\n\n\tBody\n")
+}
@@ -0,0 +1,166 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNoMatch is the error of a PatternError for a pattern that did not match any files.
+	ErrNoMatch = errors.New("did not match any files")
+	// ErrBadPattern is the error of a PatternError for a pattern that is malformed.
+	ErrBadPattern = errors.New("syntax error in pattern")
+)
+
+// Strict will turn problems with the files to parse, and the files for the build helpers, into errors rather then
+// warnings: patterns that do not match any files, malformed patterns, and entries in file lists that can not be read.
+// These are returned as a *PatternError from New, ParseFiles, and the build helpers. With Strict, a set of templates
+// that invokes a template it does not define is also an error, returned as a *ParseError from ParseFiles, or Render
+// for a page; without it, as with html/template, that is only an error if the template is executed.
+func Strict() anOption {
+	return func(t *Template) error {
+		t.strict = true
+		return nil
+	}
+}
+
+// PatternError is the error for a pattern, or file name, that did not resolve to files that can be read.
+type PatternError struct {
+	Pattern string
+	// Base is the directory the pattern is relative to.
+	Base string
+	// FileList and Line are where the pattern came from, if it came from a file list.
+	FileList string
+	Line     int
+	// Err is ErrNoMatch, ErrBadPattern, or the error reading a file.
+	Err error
+}
+
+func (pe *PatternError) Error() string {
+	if pe.FileList != "" {
+		return fmt.Sprintf("template: %v:%v: “%v” (base: %v): %v", pe.FileList, pe.Line, pe.Pattern, pe.Base, pe.Err)
+	}
+	return fmt.Sprintf("template: “%v” (base: %v): %v", pe.Pattern, pe.Base, pe.Err)
+}
+
+func (pe *PatternError) Unwrap() error { return pe.Err }
+
+// ParseError is the error for a file that could not be parsed; a template file, or a file list. It says where the file
+// was named, and where in the file the problem is; use errors.As to get at it.
+type ParseError struct {
+	// Source is where the file was named.
+	Source Source
+	File   string
+	// Line and Col are where in the file the problem is; zero if not known.
+	Line int
+	Col  int
+	// Context are the lines of the file around Line; empty if Line is not known.
+	Context []SourceLine
+	// Err is the underlying error; such as the error from html/template.
+	Err error
+
+	// name is the name of the template the file was parsed into, and msg the message of Err without the location.
+	name string
+	msg  string
+}
+
+// SourceLine is a numbered line of a file.
+type SourceLine struct {
+	Line int
+	Text string
+}
+
+// contextLines is the number of lines before and after the line of a ParseError that are kept as its Context.
+const contextLines = 2
+
+// newParseError returns the ParseError for the error parsing the text of the file into the template named name.
+// Errors from the parser start with the location of the problem; this is moved into the Line of the ParseError.
+func newParseError(file parseFile, name, text string, err error) *ParseError {
+	pe := &ParseError{Source: file.Source, File: file.File, Err: err, name: name}
+	msg := strings.TrimPrefix(err.Error(), "template: "+name+":")
+	if i := strings.Index(msg, ": "); msg != err.Error() && i > 0 {
+		if line, aerr := strconv.Atoi(msg[:i]); aerr == nil {
+			pe.Line, pe.msg = line, msg[i+2:]
+		}
+	}
+	pe.setContext(text)
+	return pe
+}
+
+// setContext sets the Context of the error from the text of the file.
+func (pe *ParseError) setContext(text string) {
+	if pe.Line <= 0 {
+		return
+	}
+	lines := strings.Split(text, "\n")
+	first, last := pe.Line-contextLines, pe.Line+contextLines
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	pe.Context = nil
+	for line := first; line <= last; line++ {
+		pe.Context = append(pe.Context, SourceLine{Line: line, Text: lines[line-1]})
+	}
+}
+
+func (pe *ParseError) Error() string {
+	location := pe.File
+	if pe.Line > 0 {
+		location = fmt.Sprintf("%v:%v", location, pe.Line)
+		if pe.Col > 0 {
+			location = fmt.Sprintf("%v:%v", location, pe.Col)
+		}
+	}
+	msg := pe.msg
+	if msg == "" {
+		msg = pe.Err.Error()
+	}
+	// A file given to ParseFile, or a file list given to ParseFileList, is its own source.
+	if pe.Source.File == "" || (pe.Source.File == pe.File && pe.Source.Line == 0) {
+		return fmt.Sprintf("template: %v: %v", location, msg)
+	}
+	return fmt.Sprintf("template: %v: %v (from %v)", location, msg, pe.Source)
+}
+
+func (pe *ParseError) Unwrap() error { return pe.Err }
+
+// Snippet returns the Context of the error as text, with line numbers, and the line of the error marked.
+func (pe *ParseError) Snippet() string {
+	var sb strings.Builder
+	for _, line := range pe.Context {
+		mark := " "
+		if line.Line == pe.Line {
+			mark = ">"
+		}
+		fmt.Fprintf(&sb, "%v %4d | %v\n", mark, line.Line, line.Text)
+	}
+	return sb.String()
+}
+
+// locateParseError fills in the file, source, and context of a ParseError that only knows the name of the template it
+// is for; such as the errors from validate. The first file parsed into that template is taken as its source. Other
+// errors are returned as is.
+func (t *Template) locateParseError(err error, files []parseFile) error {
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.File != "" {
+		return err
+	}
+	pe.File = pe.name
+	for _, file := range files {
+		if name, err := t.templateName(file); err != nil || name != pe.name {
+			continue
+		}
+		pe.File, pe.Source = file.File, file.Source
+		if b, rerr := fs.ReadFile(t.fsys, file.File); rerr == nil {
+			pe.setContext(string(b))
+		}
+		break
+	}
+	return err
+}
@@ -0,0 +1,159 @@
+package template_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplateStrict(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/parsefile.txt":       {Data: []byte("./parsefile.template\n# A typo\n./partials/*.tmpl\n")},
+		"tpl/parsefile.template":  {Data: []byte("{{buildJSFiles `views/*.jss`}}")},
+		"tpl/partials/a.template": {Data: []byte(`{{define "a"}}A{{end}}`)},
+		"views/1.js":              {Data: []byte(`alert(1);`)},
+	}
+
+	tests := []struct {
+		config   template.BConfig
+		err      error
+		expected string
+	}{
+		{
+			config:   template.BaseConfig(template.ParseFileList("tpl/parsefile.txt"), template.Strict()),
+			err:      template.ErrNoMatch,
+			expected: "template: tpl/parsefile.txt:3: “./partials/*.tmpl” (base: tpl): did not match any files",
+		},
+		{
+			config:   template.BaseConfig(template.ParseGlob("tpl/[a.template")),
+			err:      template.ErrBadPattern,
+			expected: "template: “tpl/[a.template” (base: .): syntax error in pattern",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := test.config.NewTemplate("parsefile.template", template.ParseFS(fsys), template.Strict())
+		var perr *template.PatternError
+		if !errors.As(err, &perr) || !errors.Is(err, test.err) {
+			t.Errorf("expected a PatternError for %v got %v", test.err, err)
+			continue
+		}
+		if err.Error() != test.expected {
+			t.Errorf("expected error “%v” got “%v”", test.expected, err)
+		}
+	}
+
+	// The build helpers return the error during execution.
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFS(fsys, "tpl/parsefile.template"),
+				template.AssetFS(fsys),
+				template.DistRoot(t.TempDir()),
+				template.Strict(),
+			)).ParseFiles())
+	var b bytes.Buffer
+	err := tpl.Execute(&b, nil)
+	if !errors.Is(err, template.ErrNoMatch) {
+		t.Errorf("expected build helper to fail with ErrNoMatch got %v", err)
+	}
+}
+
+func TestTemplateParseError(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/parsefile.txt":       {Data: []byte("# The page\n./parsefile.template\n./partials/*.template\n")},
+		"tpl/parsefile.template":  {Data: []byte("<html>\n{{template \"a\"}}\n{{template \"b\" .}}\n</html>\n")},
+		"tpl/partials/a.template": {Data: []byte(`{{define "a"}}A{{end}}`)},
+		"tpl/broken.template":     {Data: []byte("one\ntwo\n{{if .}}\nfour\n")},
+	}
+
+	tests := []struct {
+		config   template.BConfig
+		source   template.Source
+		file     string
+		line     int
+		col      int
+		context  []template.SourceLine
+		expected string
+	}{
+		{
+			config:   template.BaseConfig(template.ParseFileList("tpl/parsefile.txt"), template.Strict()),
+			source:   template.Source{Type: template.SrcFileList, File: "tpl/parsefile.txt", Line: 2},
+			file:     "tpl/parsefile.template",
+			line:     3,
+			col:      11,
+			context:  []template.SourceLine{{1, "<html>"}, {2, `{{template "a"}}`}, {3, `{{template "b" .}}`}, {4, "</html>"}, {5, ""}},
+			expected: `template: tpl/parsefile.template:3:11: no such template "b" (from file list tpl/parsefile.txt:2)`,
+		},
+		{
+			config:   template.BaseConfig(template.ParseGlob("tpl/broken.*")),
+			source:   template.Source{Type: template.SrcGlobFile, File: "tpl/broken.*"},
+			file:     "tpl/broken.template",
+			line:     5,
+			context:  []template.SourceLine{{3, "{{if .}}"}, {4, "four"}, {5, ""}},
+			expected: "template: tpl/broken.template:5: unexpected EOF (from glob tpl/broken.*)",
+		},
+		{
+			config:   template.BaseConfig(template.ParseFile("tpl/missing.template")),
+			source:   template.Source{Type: template.SrcParseFile, File: "tpl/missing.template"},
+			file:     "tpl/missing.template",
+			expected: "template: tpl/missing.template: open tpl/missing.template: file does not exist",
+		},
+	}
+
+	for _, test := range tests {
+		tpl, err := test.config.NewTemplate("parsefile.template", template.ParseFS(fsys))
+		if err == nil {
+			_, err = tpl.ParseFiles()
+		}
+		var perr *template.ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("expected a *ParseError, got %T: %v", err, err)
+			continue
+		}
+		if perr.Source != test.source {
+			t.Errorf("Source, expected %v got %v", test.source, perr.Source)
+		}
+		if perr.File != test.file || perr.Line != test.line || perr.Col != test.col {
+			t.Errorf("location, expected %v:%v:%v got %v:%v:%v", test.file, test.line, test.col, perr.File, perr.Line, perr.Col)
+		}
+		if !reflect.DeepEqual(perr.Context, test.context) {
+			t.Errorf("Context, expected %v got %v", test.context, perr.Context)
+		}
+		if err.Error() != test.expected {
+			t.Errorf("expected error “%v” got “%v”", test.expected, err)
+		}
+	}
+}
+
+func TestTemplateParseErrorFileList(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/parsefile.txt":      {Data: []byte("./parsefile.template\n@include shared/a.txt\n")},
+		"tpl/shared/a.txt":       {Data: []byte("# Shared\n@end\n")},
+		"tpl/parsefile.template": {Data: []byte(`Hello`)},
+	}
+
+	_, err := template.New("parsefile.template",
+		template.ParseFS(fsys),
+		template.ParseFileList("tpl/parsefile.txt"),
+	)
+	var perr *template.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected a *ParseError, got %T: %v", err, err)
+	}
+	expected := "template: tpl/shared/a.txt:2: @end without @if (from file list tpl/parsefile.txt:2)"
+	if err.Error() != expected {
+		t.Errorf("expected error “%v” got “%v”", expected, err)
+	}
+	snippet := "     1 | # Shared\n>    2 | @end\n     3 | \n"
+	if perr.Snippet() != snippet {
+		t.Errorf("Snippet, expected %q got %q", snippet, perr.Snippet())
+	}
+}
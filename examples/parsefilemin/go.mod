@@ -0,0 +1,15 @@
+module github.com/gdey/template/examples/parsefilemin
+
+go 1.21
+
+require (
+	github.com/gdey/template v0.0.0
+	github.com/tdewolff/minify v2.3.6+incompatible
+)
+
+require (
+	github.com/bmatcuk/doublestar/v4 v4.10.0 // indirect
+	github.com/tdewolff/parse v2.3.4+incompatible // indirect
+)
+
+replace github.com/gdey/template => ../..
@@ -0,0 +1,286 @@
+package template
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// parseFileList returns the files named, either directly or through globs, in the file list; globs that do not match
+// any files are warned about to logger.
+func parseFileList(t *Template, filename string, logger *slog.Logger) (parseFiles []parseFile, err error) {
+	return parseIncludedFileList(t, filename, Source{Type: SrcFileList, File: filename}, nil, logger)
+}
+
+// parseIncludedFileList returns the files named in the file list; src is where the file list was named, and included
+// is the chain of file lists that included this one, which is used to detect include cycles. Problems with the file
+// list are returned as a *ParseError.
+func parseIncludedFileList(t *Template, filename string, src Source, included []string, logger *slog.Logger) (parseFiles []parseFile, err error) {
+
+	included = append(included, filename)
+
+	listDir := dirName(t.fsys, filename)
+	// Now we need to read the file, each line of the file will be a file path comment or empty.
+	b, err := fs.ReadFile(t.fsys, filename)
+	if err != nil {
+		return nil, &ParseError{Source: src, File: filename, Err: err}
+	}
+	text := string(b)
+	errorf := func(line int, format string, args ...interface{}) error {
+		pe := &ParseError{Source: src, File: filename, Line: line, msg: fmt.Sprintf(format, args...)}
+		pe.Err = errors.New(pe.msg)
+		pe.setContext(text)
+		return pe
+	}
+	// conds is the stack of @if blocks we are in; a line is only used if all of them are true.
+	var conds []bool
+	active := func() bool {
+		for _, cond := range conds {
+			if !cond {
+				return false
+			}
+		}
+		return true
+	}
+	// We need to read each line of the file, and add the line to parse file if it does not start with
+	// # or is empty.
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for line := 1; scanner.Scan(); line++ {
+		txt := strings.TrimSpace(scanner.Text())
+		if len(txt) == 0 {
+			continue
+		}
+		if txt[0] == '#' {
+			continue
+		}
+		if txt[0] == '@' {
+			directive, arg := splitDirective(txt)
+			switch directive {
+			case "if":
+				cond, err := t.evalCondition(arg)
+				if err != nil {
+					return nil, errorf(line, "%v", err)
+				}
+				conds = append(conds, cond)
+			case "else":
+				if len(conds) == 0 {
+					return nil, errorf(line, "@else without @if")
+				}
+				conds[len(conds)-1] = !conds[len(conds)-1]
+			case "end":
+				if len(conds) == 0 {
+					return nil, errorf(line, "@end without @if")
+				}
+				conds = conds[:len(conds)-1]
+			case "include":
+				if !active() {
+					continue
+				}
+				// Included file lists are relative to the file list including them.
+				if arg == "" {
+					return nil, errorf(line, "@include is missing the file list to include")
+				}
+				if arg, err = t.expandVars(arg); err != nil {
+					return nil, errorf(line, "%v", err)
+				}
+				incFilename := arg
+				if !isOSFS(t.fsys) || !filepath.IsAbs(arg) {
+					incFilename = joinPath(t.fsys, listDir, arg)
+				}
+				for _, inc := range included {
+					if inc == incFilename {
+						return nil, errorf(line, "file list include cycle: %v", strings.Join(append(included, incFilename), " -> "))
+					}
+				}
+				incFiles, err := parseIncludedFileList(t, incFilename, Source{Type: SrcFileList, File: filename, Line: line}, included, logger)
+				if err != nil {
+					return nil, err
+				}
+				parseFiles = append(parseFiles, incFiles...)
+			default:
+				return nil, errorf(line, "unknown directive “@%v”", directive)
+			}
+			continue
+		}
+		if !active() {
+			continue
+		}
+		if txt, err = t.expandVars(txt); err != nil {
+			return nil, errorf(line, "%v", err)
+		}
+		// A line can expand to nothing, such as when a variable is empty; there is no file to list then.
+		if txt = strings.TrimSpace(txt); txt == "" {
+			continue
+		}
+		// A line can name the template of its file with “as”: admin/index.template as "admin"
+		var name string
+		if m := aliasRegexp.FindStringSubmatch(txt); m != nil {
+			txt, name = m[1], m[2]
+		}
+		// Lines starting with a ! remove the files matching the rest of the line from the files listed so far.
+		excluding := txt[0] == '!'
+		if excluding {
+			txt = strings.TrimSpace(txt[1:])
+			if len(txt) == 0 {
+				continue
+			}
+		}
+
+		var base string
+		switch txt[0] {
+		case '.':
+			base = listDir
+
+		case '/':
+
+			base = "."
+			if isOSFS(t.fsys) {
+				base, _ = os.Getwd()
+			}
+		default:
+
+			base = t.baseFor(t.fsys)
+		}
+
+		if excluding {
+			if name != "" {
+				return nil, errorf(line, "a file being removed can not be named")
+			}
+			if parseFiles, err = excludeParseFiles(t.fsys, joinPath(t.fsys, base, txt), parseFiles); err != nil {
+				return nil, errorf(line, "%v", err)
+			}
+			continue
+		}
+		matches, err := t.parsePossibleGlob(base, txt, logger)
+		var perr *PatternError
+		switch {
+		case errors.As(err, &perr):
+			perr.FileList, perr.Line = filename, line
+			return nil, perr
+		case isBadPattern(err):
+			matches = []string{joinPath(t.fsys, base, txt)}
+		case err != nil:
+			return nil, errorf(line, "%v", err)
+		}
+		if t.strict {
+			for _, match := range matches {
+				if _, err := fs.Stat(t.fsys, match); err != nil {
+					return nil, &PatternError{Pattern: txt, Base: base, FileList: filename, Line: line, Err: err}
+				}
+			}
+		}
+		if name != "" && len(matches) > 1 {
+			return nil, errorf(line, "“%v” matched %v files, but can only name one", txt, len(matches))
+		}
+		for _, match := range matches {
+			parseFiles = append(parseFiles, parseFile{File: match, Name: name, Source: Source{Type: SrcFileList, File: filename, Line: line}})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, &ParseError{Source: src, File: filename, Err: err}
+	}
+	if len(conds) != 0 {
+		return nil, errorf(0, "@if without @end")
+	}
+	return parseFiles, nil
+}
+
+// evalCondition evaluates the condition of an @if directive. A condition is one of:
+//
+//	debug        true if the template reloads; that is, its ReloadMode is not ReloadNever.
+//	name         true if the variable is set to a non-empty value.
+//	name=value   true if the variable is set to the value.
+//	name!=value  true if the variable is not set to the value.
+//
+// Any condition can be negated by starting it with a !.
+func (t *Template) evalCondition(cond string) (bool, error) {
+	cond = strings.TrimSpace(cond)
+	if strings.HasPrefix(cond, "!") && !strings.HasPrefix(cond, "!=") {
+		ok, err := t.evalCondition(cond[1:])
+		return !ok, err
+	}
+	if cond == "" {
+		return false, fmt.Errorf("@if is missing a condition")
+	}
+	if name, value, ok := strings.Cut(cond, "!="); ok {
+		return t.vars[strings.TrimSpace(name)] != strings.TrimSpace(value), nil
+	}
+	if name, value, ok := strings.Cut(cond, "="); ok {
+		return t.vars[strings.TrimSpace(name)] == strings.TrimSpace(value), nil
+	}
+	if cond == "debug" {
+		return t.mode != ReloadNever, nil
+	}
+	return t.vars[cond] != "", nil
+}
+
+// aliasRegexp matches a line that names the template of its file: file as "name"
+var aliasRegexp = regexp.MustCompile(`^(.*?)\s+as\s+"([^"]+)"$`)
+
+// excludeParseFiles returns the files to parse whose filename does not match the pattern.
+func excludeParseFiles(fsys fs.FS, pattern string, files []parseFile) ([]parseFile, error) {
+	match := matcher(fsys)
+	var kept []parseFile
+	for _, file := range files {
+		matched, err := match(pattern, file.File)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			kept = append(kept, file)
+		}
+	}
+	return kept, nil
+}
+
+var varRegexp = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// expandVars replaces each ${name} in txt with the value of the variable. It is an error to use a variable that has
+// not been set.
+func (t *Template) expandVars(txt string) (string, error) {
+	var err error
+	txt = varRegexp.ReplaceAllStringFunc(txt, func(match string) string {
+		name := strings.TrimSpace(match[2 : len(match)-1])
+		value, ok := t.vars[name]
+		if !ok && err == nil {
+			err = fmt.Errorf("undefined variable “${%v}”", name)
+		}
+		return value
+	})
+	return txt, err
+}
+
+// Vars sets variables that can be used in file lists; in @if conditions or as ${name} in a line. This option can be
+// used more then once; later values override earlier ones.
+func Vars(vars map[string]string) anOption {
+	return func(t *Template) error {
+		if t.vars == nil {
+			t.vars = make(map[string]string, len(vars))
+		}
+		for k, v := range vars {
+			t.vars[k] = v
+		}
+		return nil
+	}
+}
+
+// splitDirective splits a file list line such as “@include other.txt” into the directive and its argument; quotes
+// around the argument are removed.
+func splitDirective(txt string) (directive, arg string) {
+	txt = strings.TrimPrefix(txt, "@")
+	directive, arg = txt, ""
+	if i := strings.IndexFunc(txt, unicode.IsSpace); i != -1 {
+		directive, arg = txt[:i], strings.TrimSpace(txt[i:])
+	}
+	if len(arg) >= 2 && (arg[0] == '"' || arg[0] == '\'') && arg[len(arg)-1] == arg[0] {
+		arg = arg[1 : len(arg)-1]
+	}
+	return directive, arg
+}
@@ -0,0 +1,210 @@
+package template_test
+
+import (
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdey/template"
+)
+
+// catDist returns a helper that returns the contents of a build file in dist.
+func catDist(dist string) htmltemplate.FuncMap {
+	return htmltemplate.FuncMap{"cat": func(name string) (string, error) {
+		b, err := os.ReadFile(filepath.Join(dist, name))
+		return string(b), err
+	}}
+}
+
+func TestTemplateFileListExclude(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/parsefile.txt": {Data: []byte(`
+./parsefile.template
+tpl/partials/*.template
+# drafts are not ready yet.
+!tpl/partials/*.draft.template
+`)},
+		"tpl/parsefile.template":        {Data: []byte(`{{template "a"}}{{block "b" .}}{{end}}`)},
+		"tpl/partials/a.template":       {Data: []byte(`{{define "a"}}A{{end}}`)},
+		"tpl/partials/b.draft.template": {Data: []byte(`{{define "b"}}B{{end}}`)},
+	}
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFS(fsys),
+				template.ParseFileList("tpl/parsefile.txt"),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, nil, "A")
+}
+
+func TestTemplateBuildFileExclude(t *testing.T) {
+
+	dist := t.TempDir()
+	fsys := fstest.MapFS{
+		"bundle.template":   {Data: []byte("{{buildJSFiles `views/*.js, !views/*.test.js` | cat}}")},
+		"views/1.js":        {Data: []byte(`alert(1);`)},
+		"views/1.test.js":   {Data: []byte(`test(1);`)},
+		"views/2.js":        {Data: []byte(`alert(2);`)},
+		"views/other.js.md": {Data: []byte(`# docs`)},
+	}
+
+	tpl := template.Must(
+		template.Must(
+			template.New("bundle.template",
+				template.ParseFS(fsys, "bundle.template"),
+				template.AssetFS(fsys),
+				template.DistRoot(dist),
+				template.Helpers(catDist(dist)),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, nil, "alert(1);alert(2);")
+}
+
+func TestTemplateFileListInclude(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.txt", "./parsefile.template\n@include shared/partials.txt\n"},
+			{"shared/partials.txt", "./a.template\n"},
+			{"parsefile.template", `{{template "a"}}{{block "b" .}}{{end}}`},
+			{"shared/a.template", `{{define "a"}}A{{end}}`},
+			{"shared/b.template", `{{define "b"}}B{{end}}`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFileList("tpl/parsefile.txt"),
+				template.Reload(template.ReloadOnChange),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, nil, "A")
+
+	// Included file lists are reread on reload.
+	fixture.SetFile("shared/partials.txt", "./a.template\n./b.template\n").CreateFileOrFail(t, "shared/partials.txt")
+	ExecuteTemplateOrFail(t, tpl, nil, "AB")
+}
+
+func TestTemplateFileListIncludeCycle(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/parsefile.txt":      {Data: []byte("./parsefile.template\n@include shared/a.txt\n")},
+		"tpl/shared/a.txt":       {Data: []byte("@include \"b.txt\"\n")},
+		"tpl/shared/b.txt":       {Data: []byte("@include ../parsefile.txt\n")},
+		"tpl/parsefile.template": {Data: []byte(`Hello`)},
+	}
+
+	_, err := template.New("parsefile.template",
+		template.ParseFS(fsys),
+		template.ParseFileList("tpl/parsefile.txt"),
+	)
+	if err == nil {
+		t.Fatalf("expected an include cycle error")
+	}
+	expected := "template: tpl/shared/b.txt:1: file list include cycle: tpl/parsefile.txt -> tpl/shared/a.txt -> tpl/shared/b.txt -> tpl/parsefile.txt (from file list tpl/shared/a.txt:1)"
+	if err.Error() != expected {
+		t.Errorf("expected error “%v” got “%v”", expected, err)
+	}
+}
+
+func TestTemplateFileListConditions(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/parsefile.txt": {Data: []byte(`
+./parsefile.template
+@if debug
+./toolbar.template
+@else
+./notoolbar.template
+@end
+@if env=staging
+	@if !mock
+./${env}.template
+	@end
+@end
+@if env != staging
+./production.template
+@end
+`)},
+		"tpl/parsefile.template":  {Data: []byte(`{{block "toolbar" .}}{{end}}-{{block "env" .}}{{end}}`)},
+		"tpl/toolbar.template":    {Data: []byte(`{{define "toolbar"}}toolbar{{end}}`)},
+		"tpl/notoolbar.template":  {Data: []byte(`{{define "toolbar"}}none{{end}}`)},
+		"tpl/staging.template":    {Data: []byte(`{{define "env"}}staging{{end}}`)},
+		"tpl/production.template": {Data: []byte(`{{define "env"}}production{{end}}`)},
+	}
+
+	tests := []struct {
+		mode     template.ReloadMode
+		vars     map[string]string
+		expected string
+	}{
+		{mode: template.ReloadOnChange, vars: map[string]string{"env": "staging"}, expected: "toolbar-staging"},
+		{mode: template.ReloadNever, vars: map[string]string{"env": "staging"}, expected: "none-staging"},
+		{mode: template.ReloadNever, vars: map[string]string{"env": "staging", "mock": "yes"}, expected: "none-"},
+		{mode: template.ReloadNever, vars: map[string]string{"env": "production"}, expected: "none-production"},
+	}
+
+	for _, test := range tests {
+		tpl := template.Must(
+			template.Must(
+				template.New("parsefile.template",
+					template.ParseFS(fsys),
+					template.ParseFileList("tpl/parsefile.txt"),
+					template.Vars(test.vars),
+					template.Reload(test.mode),
+				)).ParseFiles())
+
+		ExecuteTemplateOrFail(t, tpl, nil, test.expected)
+	}
+}
+
+func TestTemplateFileListEmptyVars(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/parsefile.txt":      {Data: []byte("./parsefile.template\n${empty}\n${space}\n")},
+		"tpl/parsefile.template": {Data: []byte(`Hello`)},
+	}
+
+	// Lines that expand to nothing, or only whitespace, are skipped.
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFS(fsys),
+				template.ParseFileList("tpl/parsefile.txt"),
+				template.Vars(map[string]string{"empty": "", "space": "  \t"}),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, nil, "Hello")
+}
+
+func TestTemplateFileListConditionErrors(t *testing.T) {
+
+	tests := map[string]string{
+		"@if debug\n./parsefile.template\n": "template: tpl/parsefile.txt: @if without @end",
+		"./parsefile.template\n@end\n":      "template: tpl/parsefile.txt:2: @end without @if",
+		"./${name}.template\n":              "template: tpl/parsefile.txt:1: undefined variable “${name}”",
+		"@unless debug\n":                   "template: tpl/parsefile.txt:1: unknown directive “@unless”",
+	}
+
+	for list, expected := range tests {
+		fsys := fstest.MapFS{
+			"tpl/parsefile.txt":      {Data: []byte(list)},
+			"tpl/parsefile.template": {Data: []byte(`Hello`)},
+		}
+		_, err := template.New("parsefile.template",
+			template.ParseFS(fsys),
+			template.ParseFileList("tpl/parsefile.txt"),
+		)
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error “%v” got “%v”", expected, err)
+		}
+	}
+}
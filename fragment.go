@@ -0,0 +1,93 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+)
+
+const (
+	// DefaultFragmentHeader is the request header the handlers take the fragment to render from, unless changed with
+	// the Fragments option.
+	DefaultFragmentHeader = "X-Template-Fragment"
+	// DefaultFragmentParam is the query parameter the handlers take the fragment to render from, unless changed with
+	// the Fragments option.
+	DefaultFragmentParam = "fragment"
+)
+
+// ErrNoFragment is the error for a fragment, or the page it is asked for from, that is not defined.
+var ErrNoFragment = errors.New("template: no such fragment")
+
+// Fragments sets the request header and the query parameter the handlers take the name of the fragment to render from;
+// the header is used if both are given. An empty header or param turns that way of asking for a fragment off.
+func Fragments(header, param string) anOption {
+	return func(t *Template) error {
+		t.fragmentHeader, t.fragmentParam = header, param
+		return nil
+	}
+}
+
+// ExecuteFragment executes only the fragment, a template defined with the define or block action, of the page;
+// such as for a partial page update. The page is a page added with Pages, the name of a template associated with t,
+// or empty for t itself. The fragment is the one the page itself defines, so pages can define the same blocks
+// differently; as with Render. As with Execute, depending on the ReloadMode of the template, the files are reparsed
+// first. If the page or the fragment is not defined, the error wraps ErrNoFragment.
+func (t *Template) ExecuteFragment(w io.Writer, page, fragment string, data interface{}) error {
+	return t.execute(w, t.buffered, func(snap *snapshot, w io.Writer) error {
+		set, err := t.fragmentSet(snap, page, fragment)
+		if err != nil {
+			return err
+		}
+		return set.tmpl.ExecuteTemplate(w, fragment, data)
+	})
+}
+
+// fragmentSet returns the set of templates to execute the fragment of the page from, see ExecuteFragment: the set of
+// the page parsed into its own copy of the templates of snap; or, for a template that is not parsed from a file,
+// snap itself.
+func (t *Template) fragmentSet(snap *snapshot, page, fragment string) (*templateSet, error) {
+	name := page
+	if name == "" {
+		name = t.name
+	}
+	var set *templateSet
+	pg, err := t.page(snap, name, len(t.pageSources) == 0)
+	if len(t.pageSources) != 0 && errors.Is(err, ErrNoPage) {
+		pg, err = t.page(snap, name, true)
+	}
+	switch {
+	case err == nil:
+		set = &pg.templateSet
+	case !errors.Is(err, ErrNoPage):
+		return nil, err
+	case page == "" || defined(snap.tmpl, page):
+		set = &snap.templateSet
+	default:
+		return nil, fmt.Errorf("%w: no page %q", ErrNoFragment, page)
+	}
+	if !defined(set.tmpl, fragment) {
+		return nil, fmt.Errorf("%w: %q", ErrNoFragment, fragment)
+	}
+	return set, nil
+}
+
+// defined reports whether the template with the given name is defined in tmpl.
+func defined(tmpl *template.Template, name string) bool {
+	t := tmpl.Lookup(name)
+	return t != nil && t.Tree != nil
+}
+
+// fragmentFor returns the fragment the request asks for; empty if it does not ask for one.
+func (t *Template) fragmentFor(r *http.Request) string {
+	if t.fragmentHeader != "" {
+		if fragment := r.Header.Get(t.fragmentHeader); fragment != "" {
+			return fragment
+		}
+	}
+	if t.fragmentParam != "" {
+		return r.URL.Query().Get(t.fragmentParam)
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package template_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplateExecuteFragment(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"page.template", `<table>{{block "rows" .}}<tr>{{.}}</tr>{{end}}</table>`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("page.template",
+				template.ParseFile("tpl/page.template"),
+				template.Reload(template.ReloadOnChange),
+			)).ParseFiles())
+
+	var sb strings.Builder
+	if err := tpl.ExecuteFragment(&sb, "page.template", "rows", "a"); err != nil {
+		t.Fatalf("ExecuteFragment, expected nil error, got %v", err)
+	}
+	if sb.String() != "<tr>a</tr>" {
+		t.Errorf("ExecuteFragment, expected “<tr>a</tr>” got “%v”", sb.String())
+	}
+
+	// Fragments are reloaded like the rest of the template.
+	fixture.SetFile("page.template", `<table>{{block "rows" .}}<tr><td>{{.}}</td></tr>{{end}}</table>`).CreateFileOrFail(t, "page.template")
+	sb.Reset()
+	if err := tpl.ExecuteFragment(&sb, "", "rows", "a"); err != nil {
+		t.Fatalf("ExecuteFragment, expected nil error, got %v", err)
+	}
+	if sb.String() != "<tr><td>a</td></tr>" {
+		t.Errorf("ExecuteFragment, expected “<tr><td>a</td></tr>” got “%v”", sb.String())
+	}
+
+	for _, test := range [][2]string{{"page.template", "columns"}, {"other.template", "rows"}} {
+		if err := tpl.ExecuteFragment(&sb, test[0], test[1], "a"); !errors.Is(err, template.ErrNoFragment) {
+			t.Errorf("ExecuteFragment(%v, %v), expected ErrNoFragment got %v", test[0], test[1], err)
+		}
+	}
+}
+
+func TestTemplateExecuteFragmentOwnBlock(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/a.template":           {Data: []byte(`A{{block "content" .}}a {{.}}{{end}}`)},
+		"tpl/b.template":           {Data: []byte(`B{{block "content" .}}b {{.}}{{end}}`)},
+		"tpl/main.template":        {Data: []byte(`<main>{{template "content" .}}</main>`)},
+		"tpl/pages/home.template":  {Data: []byte(`{{define "content"}}home {{.}}{{end}}`)},
+		"tpl/pages/about.template": {Data: []byte(`{{define "content"}}about {{.}}{{end}}`)},
+	}
+
+	tpl := template.Must(
+		template.Must(
+			template.New("a.template",
+				template.ParseFS(fsys, "tpl/a.template", "tpl/b.template", "tpl/main.template"),
+				template.Pages("tpl", "pages/*.template"),
+				template.DefaultLayout("main.template"),
+				template.Reload(template.ReloadNever),
+			)).ParseFiles())
+
+	// Each page executes the block it defines itself, not the last one parsed.
+	tests := [][2]string{
+		{"a.template", "a x"},
+		{"b.template", "b x"},
+		{"", "a x"},
+		{"pages/home", "home x"},
+		{"pages/about", "about x"},
+		{"a.template", "a x"},
+	}
+	for _, test := range tests {
+		var sb strings.Builder
+		if err := tpl.ExecuteFragment(&sb, test[0], "content", "x"); err != nil {
+			t.Errorf("ExecuteFragment(%q), expected nil error, got %v", test[0], err)
+			continue
+		}
+		if sb.String() != test[1] {
+			t.Errorf("ExecuteFragment(%q), expected “%v” got “%v”", test[0], test[1], sb.String())
+		}
+	}
+}
+
+func TestTemplateHandlerFragment(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"page.template", `<table>{{block "rows" .}}<tr>{{.}}</tr>{{end}}</table>`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("page.template",
+				template.ParseFile("tpl/page.template"),
+				template.Reload(template.ReloadNever),
+				template.Logger(nil),
+			)).ParseFiles())
+	handler := tpl.Handler("", func(*http.Request) (interface{}, error) { return "a", nil })
+
+	tests := []struct {
+		url    string
+		header string
+		status int
+		body   string
+	}{
+		{url: "/", status: http.StatusOK, body: "<table><tr>a</tr></table>"},
+		{url: "/?fragment=rows", status: http.StatusOK, body: "<tr>a</tr>"},
+		{url: "/", header: "rows", status: http.StatusOK, body: "<tr>a</tr>"},
+		{url: "/?fragment=columns", status: http.StatusNotFound, body: "Not Found\n"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, test.url, nil)
+		if test.header != "" {
+			req.Header.Set(template.DefaultFragmentHeader, test.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != test.status {
+			t.Errorf("%v %v: status, expected %v got %v", test.url, test.header, test.status, rec.Code)
+		}
+		if body := rec.Body.String(); body != test.body {
+			t.Errorf("%v %v: body, expected %q got %q", test.url, test.header, test.body, body)
+		}
+		if vary := rec.Header().Get("Vary"); vary != template.DefaultFragmentHeader {
+			t.Errorf("%v %v: Vary, expected %v got %v", test.url, test.header, template.DefaultFragmentHeader, vary)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package template
+
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/gdey/template/helpers"
+)
+
+// isOSFS reports whether fsys is the operating system's file system; where names are native paths.
+func isOSFS(fsys fs.FS) bool {
+	_, ok := fsys.(helpers.OSFS)
+	return ok
+}
+
+// joinPath joins the elements into a single name that can be used with fsys.
+func joinPath(fsys fs.FS, elem ...string) string {
+	if isOSFS(fsys) {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
+
+// baseName returns the last element of name as understood by fsys.
+func baseName(fsys fs.FS, name string) string {
+	if isOSFS(fsys) {
+		return filepath.Base(name)
+	}
+	return path.Base(name)
+}
+
+// dirName returns all but the last element of name as understood by fsys.
+func dirName(fsys fs.FS, name string) string {
+	if isOSFS(fsys) {
+		return filepath.Dir(name)
+	}
+	return path.Dir(name)
+}
+
+// isBadPattern reports whether the err is the ErrBadPattern of either the path or the filepath package.
+func isBadPattern(err error) bool {
+	return err == filepath.ErrBadPattern || err == path.ErrBadPattern
+}
+
+// glob returns the names of the files in fsys that match the pattern, in lexical order. Patterns follow the doublestar
+// syntax, where “**” matches any number of directories; such as “views/**/*.js”.
+func glob(fsys fs.FS, pattern string) (matches []string, err error) {
+	if isOSFS(fsys) {
+		matches, err = doublestar.FilepathGlob(pattern, doublestar.WithFilesOnly())
+	} else {
+		matches, err = doublestar.Glob(fsys, pattern, doublestar.WithFilesOnly())
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// matcher returns the function that reports whether a name in fsys matches a pattern; the pattern uses the same
+// syntax as glob.
+func matcher(fsys fs.FS) func(pattern, name string) (bool, error) {
+	if isOSFS(fsys) {
+		return doublestar.PathMatch
+	}
+	return doublestar.Match
+}
+
+// exclude returns the names that do not match the pattern; the pattern uses the same syntax as glob.
+func exclude(fsys fs.FS, pattern string, names []string) ([]string, error) {
+	match := matcher(fsys)
+	var kept []string
+	for _, name := range names {
+		matched, err := match(pattern, name)
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			kept = append(kept, name)
+		}
+	}
+	return kept, nil
+}
+
+// baseFor returns the directory relative names are resolved against in fsys. For the operating system this is the
+// resource root, for any other fs.FS it is the root of the fs.FS; use fs.Sub to change it.
+func (t *Template) baseFor(fsys fs.FS) string {
+	if isOSFS(fsys) {
+		return t.base
+	}
+	return "."
+}
+
+// relName returns name relative to dir, with slashes as separators; ok is false if name is not under dir.
+func relName(fsys fs.FS, dir, name string) (rel string, ok bool) {
+	if isOSFS(fsys) {
+		rel, err := filepath.Rel(dir, name)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", false
+		}
+		return filepath.ToSlash(rel), true
+	}
+	dir, name = path.Clean(dir), path.Clean(name)
+	if dir == "." {
+		return name, true
+	}
+	rel = strings.TrimPrefix(name, dir+"/")
+	return rel, rel != name
+}
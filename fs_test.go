@@ -0,0 +1,119 @@
+package template_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplateParseFS(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/parsefile.template": {Data: []byte(`This is a template! {{template "include"}} {{.}}`)},
+		"tpl/include.template":   {Data: []byte(`{{define "include"}}included{{end}}`)},
+	}
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFS(fsys, "tpl/*.template"),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, "hello", "This is a template! included hello")
+}
+
+func TestTemplateParseFSFileList(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/parsefile.txt":             {Data: []byte("# The list of files\n./parsefile.template\ntpl/partials/*.template\n")},
+		"tpl/parsefile.template":        {Data: []byte(`{{template "include"}} {{.}}`)},
+		"tpl/partials/include.template": {Data: []byte(`{{define "include"}}included{{end}}`)},
+	}
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFS(fsys),
+				template.ParseFileList("tpl/parsefile.txt"),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, "hello", "included hello")
+}
+
+func TestTemplateAssetFS(t *testing.T) {
+
+	dist := t.TempDir()
+	fsys := fstest.MapFS{
+		"views/1.js": {Data: []byte(`alert(1);`)},
+		"views/2.js": {Data: []byte(`alert(2);`)},
+	}
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.template", "{{buildJSFiles `views/*.js`}}"},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFile("tpl/parsefile.template"),
+				template.AssetFS(fsys),
+				template.DistRoot(dist),
+			)).ParseFiles())
+
+	var b strings.Builder
+	if err := tpl.Execute(&b, nil); err != nil {
+		t.Fatalf("Got error executing template: %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dist, b.String()))
+	if err != nil {
+		t.Fatalf("Expected build file %v to exist: %v", b.String(), err)
+	}
+	if string(got) != `alert(1);alert(2);` {
+		t.Errorf("expected: “alert(1);alert(2);” got: “%s”", got)
+	}
+}
+
+func TestTemplateDoubleStarGlob(t *testing.T) {
+
+	dist := t.TempDir()
+	fsys := fstest.MapFS{
+		"pages/index.template":         {Data: []byte(`{{template "a"}}{{template "b"}}`)},
+		"pages/admin/a.template":       {Data: []byte(`{{define "a"}}A{{end}}`)},
+		"pages/admin/users/b.template": {Data: []byte(`{{define "b"}}B{{end}}`)},
+		"views/b/2.js":                 {Data: []byte(`alert(2);`)},
+		"views/a/deep/1.js":            {Data: []byte(`alert(1);`)},
+		"views/3.js":                   {Data: []byte(`alert(3);`)},
+	}
+
+	tpl := template.Must(
+		template.Must(
+			template.New("index.template",
+				template.ParseFS(fsys, "pages/**/*.template"),
+				template.AssetFS(fsys),
+				template.DistRoot(dist),
+				template.Helpers(catDist(dist)),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, nil, "AB")
+
+	tpl = template.Must(
+		template.Must(
+			template.New("bundle.template",
+				template.ParseFS(fstest.MapFS{
+					"bundle.template": {Data: []byte("{{buildJSFiles `views/**/*.js` | cat}}")},
+				}, "bundle.template"),
+				template.AssetFS(fsys),
+				template.DistRoot(dist),
+				template.Helpers(catDist(dist)),
+			)).ParseFiles())
+
+	// Files are ordered lexically by path.
+	ExecuteTemplateOrFail(t, tpl, nil, "alert(3);alert(1);alert(2);")
+}
@@ -0,0 +1,14 @@
+module github.com/gdey/template
+
+go 1.21
+
+require (
+	github.com/bmatcuk/doublestar/v4 v4.10.0
+	github.com/gdey/tbltest v0.0.0-20170331191646-af8abc47b052
+	github.com/tdewolff/minify v2.3.6+incompatible
+)
+
+require (
+	github.com/tdewolff/parse v2.3.4+incompatible // indirect
+	github.com/tdewolff/test v1.0.11 // indirect
+)
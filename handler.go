@@ -0,0 +1,121 @@
+package template
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// DataFunc returns the data to execute a template with, for a request. To respond with a status other than 500 when
+// it fails, return a *StatusError.
+type DataFunc func(r *http.Request) (interface{}, error)
+
+// StatusError is an error with the HTTP status to respond with.
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+func (se *StatusError) Error() string {
+	if se.Err == nil {
+		return http.StatusText(se.Status)
+	}
+	return se.Err.Error()
+}
+
+func (se *StatusError) Unwrap() error { return se.Err }
+
+// HandlerError is the data the error template, set with the ErrorTemplate option, is executed with.
+type HandlerError struct {
+	Status  int
+	Err     error
+	Request *http.Request
+}
+
+// StatusText returns the text for the status of the error; such as “Not Found”.
+func (he HandlerError) StatusText() string { return http.StatusText(he.Status) }
+
+// ErrorTemplate sets the template the handlers execute, with a HandlerError, when a request fails. Without this
+// option, or if the error template fails as well, a plain text response with the status is sent.
+func ErrorTemplate(name string) anOption {
+	return func(t *Template) error {
+		t.errorTemplate = name
+		return nil
+	}
+}
+
+// Handler returns a handler that executes the template with the given name, or the template itself if name is empty,
+// with the data returned by data for the request; data can be nil. See HandlerFunc.
+func (t *Template) Handler(name string, data DataFunc) http.Handler {
+	return t.HandlerFunc(name, data)
+}
+
+// HandlerFunc returns a handler function that executes the template with the given name, or the template itself if
+// name is empty, with the data returned by data for the request; data can be nil. If the request asks for a fragment,
+// see the Fragments option, only that fragment of the template is executed. The template is executed with
+// ExecuteContext and the context of the request, and rendered into a buffer, so that if it fails the response is an
+// error page; the error template set with the ErrorTemplate option, or the error page of the ErrorOverlay option.
+func (t *Template) HandlerFunc(name string, data DataFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var (
+			d   interface{}
+			err error
+		)
+		if data != nil {
+			if d, err = data(r); err != nil {
+				t.serveError(w, r, err)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fragment := t.fragmentFor(r)
+		if t.fragmentHeader != "" {
+			w.Header().Add("Vary", t.fragmentHeader)
+		}
+		if err = t.executeContext(r.Context(), w, name, fragment, d, true); err == nil {
+			return
+		}
+		// The error page has already been written by the overlay.
+		if t.overlaid() {
+			return
+		}
+		t.serveError(w, r, err)
+	}
+}
+
+// serveError responds to the request with the error.
+func (t *Template) serveError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) && r.Context().Err() != nil {
+		// There is no one to respond to.
+		t.log().Debug("request canceled", "path", r.URL.Path, "error", err)
+		return
+	}
+	herr := HandlerError{Status: http.StatusInternalServerError, Err: err, Request: r}
+	var serr *StatusError
+	switch {
+	case errors.As(err, &serr):
+		herr.Status = serr.Status
+	case errors.Is(err, ErrNoFragment), errors.Is(err, ErrNoPage):
+		herr.Status = http.StatusNotFound
+	}
+	if herr.Status >= http.StatusInternalServerError {
+		t.log().Error("request failed", "path", r.URL.Path, "status", herr.Status, "error", err)
+	}
+	if t.overlaid() {
+		t.ErrorPage(w, err)
+		return
+	}
+	if t.errorTemplate != "" {
+		buf := getBuffer()
+		defer putBuffer(buf)
+		terr := t.executeContext(r.Context(), buf, t.errorTemplate, "", herr, false)
+		if terr == nil {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(herr.Status)
+			buf.WriteTo(w)
+			return
+		}
+		t.log().Error("error template failed", "template", t.errorTemplate, "error", terr)
+	}
+	http.Error(w, http.StatusText(herr.Status), herr.Status)
+}
@@ -0,0 +1,92 @@
+package template_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplateHandler(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/index.template": {Data: []byte(`{{define "page"}}partial {{index . 0}}{{end}}{{define "error"}}{{.Status}} {{.StatusText}}{{end}}`)},
+	}
+
+	data := func(r *http.Request) (interface{}, error) {
+		switch name := r.URL.Query().Get("name"); name {
+		case "missing":
+			return nil, &template.StatusError{Status: http.StatusNotFound, Err: errors.New("no such page")}
+		case "":
+			return []string{}, nil
+		default:
+			return []string{name}, nil
+		}
+	}
+
+	tests := []struct {
+		errorTemplate string
+		url           string
+		status        int
+		contentType   string
+		body          string
+	}{
+		{url: "/?name=bob", status: http.StatusOK, contentType: "text/html; charset=utf-8", body: "partial bob"},
+		{url: "/?name=missing", errorTemplate: "error", status: http.StatusNotFound, contentType: "text/html; charset=utf-8", body: "404 Not Found"},
+		{url: "/", errorTemplate: "error", status: http.StatusInternalServerError, contentType: "text/html; charset=utf-8", body: "500 Internal Server Error"},
+		{url: "/", status: http.StatusInternalServerError, contentType: "text/plain; charset=utf-8", body: "Internal Server Error\n"},
+		{url: "/?name=missing", status: http.StatusNotFound, contentType: "text/plain; charset=utf-8", body: "Not Found\n"},
+	}
+
+	for _, test := range tests {
+		tpl := template.Must(
+			template.Must(
+				template.New("index.template",
+					template.ParseFS(fsys, "tpl/index.template"),
+					template.Reload(template.ReloadNever),
+					template.ErrorTemplate(test.errorTemplate),
+					template.Logger(nil),
+				)).ParseFiles())
+
+		rec := httptest.NewRecorder()
+		tpl.Handler("page", data).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.url, nil))
+		if rec.Code != test.status {
+			t.Errorf("%v: status, expected %v got %v", test.url, test.status, rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != test.contentType {
+			t.Errorf("%v: Content-Type, expected %v got %v", test.url, test.contentType, ct)
+		}
+		if body := rec.Body.String(); body != test.body {
+			t.Errorf("%v: body, expected %q got %q", test.url, test.body, body)
+		}
+	}
+}
+
+func TestTemplateHandlerErrorOverlay(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/index.template": {Data: []byte(`partial {{index . 0}}`)},
+	}
+
+	tpl := template.Must(
+		template.Must(
+			template.New("index.template",
+				template.ParseFS(fsys, "tpl/index.template"),
+				template.Reload(template.ReloadOnChange),
+				template.ErrorOverlay(),
+				template.Logger(nil),
+			)).ParseFiles())
+
+	rec := httptest.NewRecorder()
+	tpl.HandlerFunc("", nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status, expected %v got %v", http.StatusInternalServerError, rec.Code)
+	}
+	if body := rec.Body.String(); !strings.HasPrefix(body, "<!DOCTYPE html>") || strings.Count(body, "<!DOCTYPE html>") != 1 {
+		t.Errorf("expected a single error page, got %v", body)
+	}
+}
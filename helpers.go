@@ -2,11 +2,11 @@ package template
 
 import (
 	"crypto/sha1"
+	"errors"
 	"fmt"
 	"html/template"
-	"log"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"log/slog"
 	"strings"
 
 	"github.com/gdey/template/helpers"
@@ -19,34 +19,40 @@ func makeKey(filenames []string) (key string) {
 	return fmt.Sprintf("%x", sha1.Sum([]byte(key)))
 }
 
-func filepatternToFilenames(base string, patterns []string) (filenames []string, err error) {
+// filepatternToFilenames returns the files matching the patterns; in the order of the patterns. If strict is set, a
+// pattern that is malformed or does not match any files is returned as a *PatternError, otherwise it is logged.
+func filepatternToFilenames(fsys fs.FS, base string, patterns []string, strict bool, logger *slog.Logger) (filenames []string, err error) {
 
 	for _, pat := range patterns {
-		glob := filepath.Join(base, pat)
+		// Patterns starting with a ! remove the matching files from the files matched so far.
+		if strings.HasPrefix(pat, "!") {
+			pattern := joinPath(fsys, base, strings.TrimSpace(pat[1:]))
+			if filenames, err = exclude(fsys, pattern, filenames); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		pattern := joinPath(fsys, base, pat)
 
-		files, err := filepath.Glob(glob)
-		if err != nil {
+		files, err := glob(fsys, pattern)
+		switch {
+		case strict && isBadPattern(err):
+			return nil, &PatternError{Pattern: pat, Base: base, Err: ErrBadPattern}
+		case err != nil:
 			return nil, err
+		case strict && len(files) == 0:
+			return nil, &PatternError{Pattern: pat, Base: base, Err: ErrNoMatch}
 		}
 		if len(files) == 0 {
-			wd, err := os.Getwd()
-			if err != nil {
-				log.Printf("unable to get working directory(%v)", err)
-				// We are setting wd for the following message; it's not used any where else.
-				wd = "UNKNOWN"
-			}
-			log.Printf("WARNING: Glob(%v) did not match any files. Base Directory: (%v)", glob, wd)
+			logger.Warn("glob did not match any files", "pattern", pat, "base", base)
 		}
 		filenames = append(filenames, files...)
 	}
 	return filenames, nil
 }
 
-// BuildMimeTypeFile is a helper function that takes a MimeType and a set of filenames and generated a combined (minimizied if a minimizer is provided)
-// file.
-func (t *Template) BuildMimeTypeFile(mimetype string, fnames string) (filename string, err error) {
-
-	var patterns []string
+// splitPatterns splits the comma separated patterns given to the build helpers.
+func splitPatterns(fnames string) (patterns []string) {
 	for _, fname := range strings.Split(fnames, ",") {
 		fn := strings.TrimSpace(fname)
 		if fn == "" {
@@ -54,23 +60,75 @@ func (t *Template) BuildMimeTypeFile(mimetype string, fnames string) (filename s
 		}
 		patterns = append(patterns, fn)
 	}
-	filenames, err := filepatternToFilenames(t.base, patterns)
+	return patterns
+}
+
+// buildFile is a build file that was asked for by a template.
+type buildFile struct {
+	mimetype string
+	fnames   string
+}
+
+// BuildMimeTypeFile is a helper function that takes a MimeType and a set of filenames and generated a combined (minimizied if a minimizer is provided)
+// file.
+func (t *Template) BuildMimeTypeFile(mimetype string, fnames string) (filename string, err error) {
+	return t.buildMimeTypeFile(mimetype, fnames, false)
+}
+
+// rebuildFiles will rebuild all the build files the template has been asked for so far.
+func (t *Template) rebuildFiles() error {
+	t.buildLock.Lock()
+	builds := make([]buildFile, 0, len(t.buildFiles))
+	for build := range t.buildFiles {
+		builds = append(builds, build)
+	}
+	t.buildLock.Unlock()
+
+	var errs []error
+	for _, build := range builds {
+		if _, err := t.buildMimeTypeFile(build.mimetype, build.fnames, true); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// buildMimeTypeFile does the work for BuildMimeTypeFile; if force is set the file is always rebuilt.
+func (t *Template) buildMimeTypeFile(mimetype string, fnames string, force bool) (filename string, err error) {
+
+	filenames, err := filepatternToFilenames(t.assetFS, t.baseFor(t.assetFS), splitPatterns(fnames), t.strict, t.log())
 	if err != nil {
 		return "", err
 	}
 	key := makeKey(filenames)
+	// Builds are done while holding the lock, so two executions asking for the same build file do not write it
+	// at the same time.
 	t.buildLock.Lock()
+	defer t.buildLock.Unlock()
+	t.buildFiles[buildFile{mimetype, fnames}] = struct{}{}
 	oldFilename := t.buildFileOldFilenameCaché[key]
-	dest := t.dist
-	t.buildLock.Unlock()
+	var stamps fileStamps
+	switch {
+	case force || t.mode == ReloadAlways:
+		// An empty old filename forces the build file to be rebuilt.
+		oldFilename = ""
+	case t.mode == ReloadOnChange:
+		stamps = stampFiles(t.assetFS, filenames...)
+		if !stamps.equal(t.buildStamps[key]) {
+			oldFilename = ""
+		}
+	}
 
-	if filename, err = helpers.BuildFile(dest, t.minifiers[mimetype], mimetype, oldFilename, filenames...); err != nil {
+	if filename, err = helpers.BuildFileFS(t.assetFS, t.dist, t.minifiers[mimetype], mimetype, oldFilename, filenames...); err != nil {
+		t.log().Error("build failed", "mimetype", mimetype, "patterns", fnames, "error", err)
 		return filename, err
 	}
-	if oldFilename != filename {
-		t.buildLock.Lock()
-		t.buildFileOldFilenameCaché[key] = filename
-		t.buildLock.Unlock()
+	if filename != oldFilename {
+		t.log().Debug("built file", "file", filename, "mimetype", mimetype, "dist", t.dist, "sources", len(filenames))
+	}
+	t.buildFileOldFilenameCaché[key] = filename
+	if stamps != nil {
+		t.buildStamps[key] = stamps
 	}
 	return filename, err
 }
@@ -0,0 +1,23 @@
+package helpers
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// OSFS is an fs.FS backed by the operating system's file system. Unlike os.DirFS it is not rooted; names are
+// native paths that are either absolute or relative to the current working directory.
+type OSFS struct{}
+
+// Open opens the named file for reading.
+func (OSFS) Open(name string) (fs.File, error) { return os.Open(name) }
+
+// Stat returns the FileInfo for the named file.
+func (OSFS) Stat(name string) (fs.FileInfo, error) { return os.Stat(name) }
+
+// ReadFile reads the named file and returns its contents.
+func (OSFS) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+
+// Glob returns the names of all files matching pattern.
+func (OSFS) Glob(pattern string) ([]string, error) { return filepath.Glob(pattern) }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -18,9 +19,6 @@ type Minifier interface {
 	Minify(mimetype string, w io.Writer, r io.Reader) error
 }
 
-// ReloadAlways controls weather the system will reload files ignoring the file existence check.
-var ReloadAlways bool
-
 /*
 This file contains the helpers that come with the templates.
 */
@@ -136,8 +134,15 @@ func moveFileContents(src, dst string) (err error) {
 // As the order of the files can be important we take the order of the file provided.
 // If a file is listed more then once, only the first listing will be included.
 func BuildFile(dist string, min Minifier, mimetype, oldname string, filenames ...string) (filename string, err error) {
+	return BuildFileFS(OSFS{}, dist, min, mimetype, oldname, filenames...)
+}
+
+// BuildFileFS is the same as BuildFile, but the source files are read from fsys. The build file is always written to
+// dist on the operating system's file system.
+func BuildFileFS(fsys fs.FS, dist string, min Minifier, mimetype, oldname string, filenames ...string) (filename string, err error) {
 	// First we have to check if the oldname file exists. If it does, then we don't do anything.
-	if oldname != "" && !ReloadAlways {
+	// An empty oldname will always rebuild the file.
+	if oldname != "" {
 
 		// If the file already exists on the File system do nothing.
 		filename = filepath.Join(dist, oldname)
@@ -173,7 +178,7 @@ DONE_PREFIX:
 			continue
 		}
 		filesum = filesum.Set(filename, "")
-		file, err := os.Open(filename)
+		file, err := fsys.Open(filename)
 		if err != nil {
 			be = append(be, FileError{filename, err})
 			continue
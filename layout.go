@@ -0,0 +1,279 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// pageSource is a set of page files; the files matching the patterns under root.
+type pageSource struct {
+	root     string
+	patterns []string
+}
+
+// page is a page parsed into its own copy of the templates of a snapshot.
+type page struct {
+	templateSet
+	// layout is the name of the template to execute; the page itself if it has no layout.
+	layout string
+	file   string
+	stamp  fileStamp
+}
+
+// Pages adds the files matching the patterns under root, which is relative to the resource root, as pages that can
+// be rendered with Render; if no patterns are given all the files under root are pages. A page is named by its path
+// relative to root, without the extension; so with Pages("tpl", "pages/**/*.template") the file
+// “tpl/pages/home.template” is the page “pages/home”. With pages and the Strict option, the templates can invoke
+// templates that only the pages define, such as the blocks of a layout; each page is validated again once combined
+// with the templates.
+func Pages(root string, patterns ...string) anOption {
+	return func(t *Template) error {
+		if len(patterns) == 0 {
+			patterns = []string{"**/*"}
+		}
+		t.pageSources = append(t.pageSources, pageSource{root: root, patterns: patterns})
+		return nil
+	}
+}
+
+// DefaultLayout sets the template that pages are rendered with, unless a page names its own layout. The layout is a
+// template from the files of the template, that invokes the blocks the pages define; for example:
+//
+//	<html><body>{{block "content" .}}{{end}}</body></html>
+//
+// A page names its own layout, or none to be rendered by itself, with a comment at the start of the page:
+//
+//	{{/* layout: admin.template */}}
+//	{{define "content"}}...{{end}}
+func DefaultLayout(name string) anOption {
+	return func(t *Template) error {
+		t.defaultLayout = name
+		return nil
+	}
+}
+
+// ErrNoPage is the error for a page that is not found.
+var ErrNoPage = errors.New("template: no such page")
+
+// layoutRegexp matches the comment, at the start of a page, that names the layout of the page.
+var layoutRegexp = regexp.MustCompile(`^\s*/\*\s*layout:\s*(\S+)\s*\*/`)
+
+// pageLayout returns the layout the text of a page names, and whether it names one.
+func pageLayout(text, leftDelim string) (string, bool) {
+	if leftDelim == "" {
+		leftDelim = "{{"
+	}
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, leftDelim) {
+		return "", false
+	}
+	m := layoutRegexp.FindStringSubmatch(strings.TrimPrefix(text[len(leftDelim):], "-"))
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// Render executes the page, combined with its layout, with the given data. Each page is parsed into its own copy of
+// the templates, so pages can define the same blocks differently. As with Execute, depending on the ReloadMode of the
+// template, the files, and the page, are reparsed first.
+func (t *Template) Render(w io.Writer, name string, data interface{}) error {
+	return t.execute(w, t.buffered, func(snap *snapshot, w io.Writer) error {
+		pg, err := t.page(snap, name, false)
+		if err != nil {
+			return err
+		}
+		return pg.tmpl.ExecuteTemplate(w, pg.layout, data)
+	})
+}
+
+// PageNames returns the names of the pages that can be rendered, in order.
+func (t *Template) PageNames() ([]string, error) {
+	files, err := t.pageFiles()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// pageFiles returns the files of the pages, by the name of the page. If more then one file has the same page name, the
+// first one found is used.
+func (t *Template) pageFiles() (map[string]parseFile, error) {
+	base := t.baseFor(t.fsys)
+	files := make(map[string]parseFile)
+	for _, src := range t.pageSources {
+		root := joinPath(t.fsys, base, src.root)
+		for _, pattern := range src.patterns {
+			matches, err := glob(t.fsys, joinPath(t.fsys, root, pattern))
+			if isBadPattern(err) {
+				return nil, &PatternError{Pattern: pattern, Base: root, Err: ErrBadPattern}
+			}
+			if err != nil {
+				return nil, err
+			}
+			for _, match := range matches {
+				rel, ok := relName(t.fsys, root, match)
+				if !ok {
+					continue
+				}
+				name := strings.TrimSuffix(rel, path.Ext(rel))
+				if _, ok := files[name]; !ok {
+					files[name] = parseFile{File: match, Name: name, Source: Source{Type: SrcGlobFile, File: joinPath(t.fsys, src.root, pattern)}}
+				}
+			}
+		}
+	}
+	return files, nil
+}
+
+// pageKey is the key of a page in the pages of a template; set is for a template of the set, rather than a page
+// added with Pages.
+type pageKey struct {
+	name string
+	set  bool
+}
+
+// pageTemplates returns the names of the templates the pages define. Pages that can not be read or parsed are skipped;
+// their errors are reported when they are rendered.
+func (t *Template) pageTemplates() map[string]bool {
+	files, err := t.pageFiles()
+	if err != nil {
+		return nil
+	}
+	names := make(map[string]bool)
+	for _, file := range files {
+		b, err := fs.ReadFile(t.fsys, file.File)
+		if err != nil {
+			continue
+		}
+		trees := make(map[string]*parse.Tree)
+		tree := parse.New(file.Name)
+		// The helpers are not known here, and do not matter for the names of the templates.
+		tree.Mode = parse.SkipFuncCheck
+		if _, err := tree.Parse(string(b), t.leftDelim, t.rightDelim, trees); err != nil {
+			continue
+		}
+		names[file.Name] = true
+		for name := range trees {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// page returns the page with the given name, parsed into a copy of the templates of snap. If set is true the page is
+// the template with the given name parsed from the files of snap, which is parsed again so the blocks it defines win;
+// it is rendered by itself. Pages are kept until snap is replaced, or, depending on the ReloadMode of the template, the
+// file of the page changes.
+func (t *Template) page(snap *snapshot, name string, set bool) (*page, error) {
+	t.pagesLock.Lock()
+	defer t.pagesLock.Unlock()
+	if t.pagesFor != snap {
+		t.pages, t.pagesFor = make(map[pageKey]*page), snap
+	}
+	key := pageKey{name: name, set: set}
+	pg := t.pages[key]
+	switch {
+	case pg == nil:
+	case t.mode == ReloadNever:
+		return pg, nil
+	case t.mode == ReloadOnChange && stampFile(t.fsys, pg.file).equal(pg.stamp):
+		return pg, nil
+	}
+
+	var (
+		file parseFile
+		ok   bool
+	)
+	if set {
+		file, ok = t.setFile(snap, name)
+	} else {
+		files, err := t.pageFiles()
+		if err != nil {
+			return nil, err
+		}
+		file, ok = files[name]
+	}
+	if !ok {
+		// The file of the page may have been removed since it was parsed.
+		delete(t.pages, key)
+		return nil, fmt.Errorf("%w: %q", ErrNoPage, name)
+	}
+	pg, err := t.parsePage(snap, file, !set)
+	if err != nil {
+		return nil, err
+	}
+	t.pages[key] = pg
+	return pg, nil
+}
+
+// setFile returns the file the template with the given name was parsed from, named for the template; the first one if
+// there are more.
+func (t *Template) setFile(snap *snapshot, name string) (parseFile, bool) {
+	for _, file := range snap.files {
+		if fname, err := t.templateName(file); err == nil && fname == name {
+			file.Name = name
+			return file, true
+		}
+	}
+	return parseFile{}, false
+}
+
+// parsePage parses the file of a page into a copy of the templates of snap. Unless layouts is set, the page is
+// rendered by itself, whatever layout it names.
+func (t *Template) parsePage(snap *snapshot, file parseFile, layouts bool) (*page, error) {
+	stamp := stampFile(t.fsys, file.File)
+	b, err := fs.ReadFile(t.fsys, file.File)
+	if err != nil {
+		return nil, &ParseError{Source: file.Source, File: file.File, Err: err}
+	}
+	text := string(b)
+	tmpl, err := snap.pristine.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tpl := tmpl
+	if file.Name != tmpl.Name() {
+		tpl = tmpl.New(file.Name)
+	}
+	if _, err = tpl.Parse(text); err != nil {
+		return nil, newParseError(file, file.Name, text, err)
+	}
+	layout, ok := pageLayout(text, t.leftDelim)
+	switch {
+	case !layouts:
+		layout = ""
+	case !ok:
+		layout = t.defaultLayout
+	case layout == "none":
+		layout = ""
+	}
+	if layout == "" {
+		layout = file.Name
+	}
+	if !defined(tmpl, layout) {
+		return nil, fmt.Errorf("template: page %q: no such layout %q", file.Name, layout)
+	}
+	if t.strict {
+		if err = validateFrom(tmpl, layout); err != nil {
+			return nil, t.locateParseError(err, append([]parseFile{file}, snap.files...))
+		}
+	}
+	set, err := t.newTemplateSet(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return &page{templateSet: set, layout: layout, file: file.File, stamp: stamp}, nil
+}
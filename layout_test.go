@@ -0,0 +1,123 @@
+package template_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplateRender(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/layouts/main.template":      {Data: []byte(`<title>{{block "title" .}}Site{{end}}</title>{{template "content" .}}`)},
+		"tpl/layouts/admin.template":     {Data: []byte(`[admin]{{template "content" .}}`)},
+		"tpl/partials/nav.template":      {Data: []byte(`{{define "nav"}}<nav>{{.}}</nav>{{end}}`)},
+		"tpl/pages/home.template":        {Data: []byte(`{{define "content"}}{{template "nav" .}}home{{end}}`)},
+		"tpl/pages/about.template":       {Data: []byte(`{{define "title"}}About{{end}}{{define "content"}}about{{end}}`)},
+		"tpl/pages/admin/users.template": {Data: []byte("{{/* layout: admin.template */}}\n{{define \"content\"}}users{{end}}")},
+		"tpl/pages/plain.template":       {Data: []byte(`{{/* layout: none */}}plain {{.}}`)},
+		"tpl/pages/broken.template":      {Data: []byte(`{{define "content"}}{{template "missing"}}{{end}}`)},
+	}
+
+	tpl := template.Must(
+		template.Must(
+			template.New("main.template",
+				template.ParseFS(fsys, "tpl/layouts/*.template", "tpl/partials/*.template"),
+				template.Pages("tpl", "pages/**/*.template"),
+				template.DefaultLayout("main.template"),
+				template.Reload(template.ReloadOnChange),
+				template.Strict(),
+			)).ParseFiles())
+
+	names, err := tpl.PageNames()
+	if err != nil {
+		t.Fatalf("PageNames, expected nil error, got %v", err)
+	}
+	expectedNames := []string{"pages/about", "pages/admin/users", "pages/broken", "pages/home", "pages/plain"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("PageNames, expected %v got %v", expectedNames, names)
+	}
+
+	tests := []struct {
+		page     string
+		expected string
+	}{
+		{page: "pages/home", expected: "<title>Site</title><nav>x</nav>home"},
+		{page: "pages/about", expected: "<title>About</title>about"},
+		// Pages do not see each others definitions.
+		{page: "pages/home", expected: "<title>Site</title><nav>x</nav>home"},
+		{page: "pages/admin/users", expected: "[admin]users"},
+		{page: "pages/plain", expected: "plain x"},
+	}
+
+	for _, test := range tests {
+		var sb strings.Builder
+		if err := tpl.Render(&sb, test.page, "x"); err != nil {
+			t.Errorf("Render(%v), expected nil error, got %v", test.page, err)
+			continue
+		}
+		if sb.String() != test.expected {
+			t.Errorf("Render(%v), expected “%v” got “%v”", test.page, test.expected, sb.String())
+		}
+	}
+
+	var sb strings.Builder
+	if err := tpl.Render(&sb, "pages/missing", nil); !errors.Is(err, template.ErrNoPage) {
+		t.Errorf("Render(pages/missing), expected ErrNoPage got %v", err)
+	}
+	err = tpl.Render(&sb, "pages/broken", nil)
+	var perr *template.ParseError
+	if !errors.As(err, &perr) || perr.File != "tpl/pages/broken.template" {
+		t.Errorf("Render(pages/broken), expected a ParseError for tpl/pages/broken.template got %v", err)
+	}
+
+	// Pages are reloaded when they change.
+	fsys["tpl/pages/home.template"] = &fstest.MapFile{Data: []byte(`{{define "content"}}new home{{end}}`)}
+	sb.Reset()
+	if err := tpl.Render(&sb, "pages/home", nil); err != nil || sb.String() != "<title>Site</title>new home" {
+		t.Errorf("Render(pages/home), expected “<title>Site</title>new home” got “%v” (%v)", sb.String(), err)
+	}
+}
+
+func TestTemplatePagesValidate(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/layouts/main.template": {Data: []byte(`<main>{{template "content" .}}</main>`)},
+		"tpl/partials/nav.template": {Data: []byte(`{{define "nav"}}<nav>{{template "navitem" .}}</nav>{{end}}`)},
+		"tpl/pages/home.template":   {Data: []byte(`{{define "content"}}home{{end}}`)},
+	}
+
+	// The layout may invoke the content the pages define, but the partial invokes a template no one defines.
+	tpl := template.Must(
+		template.New("main.template",
+			template.ParseFS(fsys, "tpl/layouts/*.template", "tpl/partials/*.template"),
+			template.Pages("tpl", "pages/*.template"),
+			template.DefaultLayout("main.template"),
+			template.Reload(template.ReloadNever),
+			template.Strict(),
+		))
+	_, err := tpl.ParseFiles()
+	var perr *template.ParseError
+	if !errors.As(err, &perr) || perr.File != "tpl/partials/nav.template" {
+		t.Fatalf("ParseFiles, expected a ParseError for tpl/partials/nav.template got %v", err)
+	}
+
+	fsys["tpl/partials/nav.template"] = &fstest.MapFile{Data: []byte(`{{define "nav"}}<nav>{{.}}</nav>{{end}}`)}
+	tpl = template.Must(
+		template.Must(
+			template.New("main.template",
+				template.ParseFS(fsys, "tpl/layouts/*.template", "tpl/partials/*.template"),
+				template.Pages("tpl", "pages/*.template"),
+				template.DefaultLayout("main.template"),
+				template.Reload(template.ReloadNever),
+				template.Strict(),
+			)).ParseFiles())
+	var sb strings.Builder
+	if err := tpl.Render(&sb, "pages/home", nil); err != nil || sb.String() != "<main>home</main>" {
+		t.Errorf("Render(pages/home), expected “<main>home</main>” got “%v” (%v)", sb.String(), err)
+	}
+}
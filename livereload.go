@@ -0,0 +1,125 @@
+package template
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	// DefaultLiveReloadURL is the URL the liveReload helper connects to, unless changed with the LiveReload option.
+	DefaultLiveReloadURL = "/_template/livereload"
+	// DefaultLiveReloadInterval is how often the LiveReloadHandler checks for changes, unless changed with the
+	// LiveReload option.
+	DefaultLiveReloadInterval = 500 * time.Millisecond
+)
+
+// LiveReload sets the URL the LiveReloadHandler of the template is served at, and how often it checks the files for
+// changes; an empty url or a zero interval keeps the default.
+func LiveReload(url string, interval time.Duration) anOption {
+	return func(t *Template) error {
+		if url != "" {
+			t.liveReloadURL = url
+		}
+		if interval > 0 {
+			t.liveReloadInterval = interval
+		}
+		return nil
+	}
+}
+
+// LiveReloadScript returns the script that reloads the page when the LiveReloadHandler reports a change; it is the
+// liveReload helper. If the template does not reload (its ReloadMode is ReloadNever) the script is empty, so the
+// helper can be left in production templates.
+func (t *Template) LiveReloadScript() template.HTML {
+	if t.mode == ReloadNever {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf(`<script type="text/javascript">(function(){var es=new EventSource("%v");es.addEventListener("reload",function(){es.close();window.location.reload();});})();</script>`,
+		template.JSEscapeString(t.liveReloadURL)))
+}
+
+// LiveReloadHandler returns the handler, to be served at the URL set by the LiveReload option, that the liveReload
+// helper connects to. It is a Server-Sent Events stream that sends a reload event every time the files of the
+// template, or the files of the build files it has been asked for, change. If the template does not reload the
+// handler responds with not found.
+func (t *Template) LiveReloadHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if t.mode == ReloadNever || !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		stamps := t.sourceStamps()
+		io.WriteString(w, ": watching for changes\n\n")
+		flusher.Flush()
+
+		ticker := time.NewTicker(t.liveReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+			}
+			current := t.sourceStamps()
+			if current.equal(stamps) {
+				continue
+			}
+			stamps = current
+			t.log().Debug("files changed, sending live reload")
+			if _, err := io.WriteString(w, "event: reload\ndata: reload\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	})
+}
+
+// sourceStamps returns the stamps of all the files that go into the template: the file lists and files it parses,
+// and the files of the build files it has been asked for.
+func (t *Template) sourceStamps() fileStamps {
+	t.parseLock.Lock()
+	sources := append([]parseFileSources(nil), t.parseFilesSources...)
+	t.parseLock.Unlock()
+
+	var stamps fileStamps
+	// The file lists themselves are stamped, so a change is noticed even if the list is broken.
+	for _, src := range sources {
+		if src.Type != SrcGlobFile {
+			stamps = append(stamps, stampFile(t.fsys, src.File))
+		}
+	}
+	// This runs on every tick; globs that do not match have already been warned about when the template was parsed.
+	quiet := slog.New(discardHandler{})
+	if files, err := genParseFileList(t, quiet); err == nil {
+		stamps = append(stamps, stampFiles(t.fsys, filenamesOf(files)...)...)
+	}
+
+	t.buildLock.Lock()
+	builds := make([]buildFile, 0, len(t.buildFiles))
+	for build := range t.buildFiles {
+		builds = append(builds, build)
+	}
+	t.buildLock.Unlock()
+	sort.Slice(builds, func(i, j int) bool {
+		if builds[i].mimetype != builds[j].mimetype {
+			return builds[i].mimetype < builds[j].mimetype
+		}
+		return builds[i].fnames < builds[j].fnames
+	})
+	// Patterns that do not match have already been warned about when the build file was built.
+	for _, build := range builds {
+		filenames, err := filepatternToFilenames(t.assetFS, t.baseFor(t.assetFS), splitPatterns(build.fnames), false, quiet)
+		if err == nil {
+			stamps = append(stamps, stampFiles(t.assetFS, filenames...)...)
+		}
+	}
+	return stamps
+}
@@ -0,0 +1,128 @@
+package template_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplateLiveReload(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.template", `<head>{{liveReload}}</head>`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFile("tpl/parsefile.template"),
+				template.Reload(template.ReloadOnChange),
+				template.LiveReload("/reload", 10*time.Millisecond),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, nil, `<head><script type="text/javascript">(function(){var es=new EventSource("/reload");es.addEventListener("reload",function(){es.close();window.location.reload();});})();</script></head>`)
+
+	srv := httptest.NewServer(tpl.LiveReloadHandler())
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("expected to connect to the live reload handler: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type, expected text/event-stream got %v", ct)
+	}
+	events := bufio.NewReader(resp.Body)
+	if line, _ := events.ReadString('\n'); !strings.HasPrefix(line, ":") {
+		t.Fatalf("expected a comment when connecting, got %q", line)
+	}
+
+	fixture.SetFile("parsefile.template", `<head>changed {{liveReload}}</head>`).CreateFileOrFail(t, "parsefile.template")
+	for {
+		line, err := events.ReadString('\n')
+		if err != nil {
+			t.Fatalf("expected a reload event: %v", err)
+		}
+		if line == "event: reload\n" {
+			break
+		}
+	}
+}
+
+func TestTemplateLiveReloadQuiet(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.template", `<head>{{liveReload}}</head>`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	var buf bytes.Buffer
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseGlob("tpl/parsefile.template", "tpl/*.tmpl"),
+				template.Reload(template.ReloadOnChange),
+				template.LiveReload("/reload", time.Millisecond),
+				template.Logger(slog.New(slog.NewTextHandler(&buf, nil))),
+			)).ParseFiles())
+	warnings := strings.Count(buf.String(), "glob did not match any files")
+
+	srv := httptest.NewServer(tpl.LiveReloadHandler())
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if resp, err := http.DefaultClient.Do(req); err == nil {
+		<-ctx.Done()
+		resp.Body.Close()
+	}
+	srv.Close()
+
+	if got := strings.Count(buf.String(), "glob did not match any files"); got != warnings {
+		t.Errorf("warnings, expected the live reload handler to not warn again, got %v more", got-warnings)
+	}
+}
+
+func TestTemplateLiveReloadNever(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.template", `<head>{{liveReload}}</head>`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFile("tpl/parsefile.template"),
+				template.Reload(template.ReloadNever),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, nil, `<head></head>`)
+
+	rec := httptest.NewRecorder()
+	tpl.LiveReloadHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, template.DefaultLiveReloadURL, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status, expected %v got %v", http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,31 @@
+package template
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Logger sets the logger the template writes its warnings and errors to; and, at the debug level, its reloads and the
+// files it builds. Without this option the template uses slog.Default() as it is when the template is created; a nil
+// logger silences the template.
+func Logger(logger *slog.Logger) anOption {
+	return func(t *Template) error {
+		if logger == nil {
+			t.logger = slog.New(discardHandler{})
+			return nil
+		}
+		t.logger = logger.With("template", t.name)
+		return nil
+	}
+}
+
+// log returns the logger of the template, which has the name of the template attached.
+func (t *Template) log() *slog.Logger { return t.logger }
+
+// discardHandler is a slog.Handler that discards everything.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (dh discardHandler) WithAttrs([]slog.Attr) slog.Handler     { return dh }
+func (dh discardHandler) WithGroup(string) slog.Handler          { return dh }
@@ -0,0 +1,90 @@
+package template_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplateLogger(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/index.template": {Data: []byte(`index`)},
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	_, err := template.New("index.template",
+		template.ParseFS(fsys, "tpl/index.template", "tpl/*.tmpl"),
+		template.Reload(template.ReloadNever),
+		template.Logger(logger),
+	)
+	if err != nil {
+		t.Fatalf("New, expected nil error, got %v", err)
+	}
+
+	var record struct {
+		Level    string `json:"level"`
+		Msg      string `json:"msg"`
+		Template string `json:"template"`
+		Pattern  string `json:"pattern"`
+		Base     string `json:"base"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("expected a single json log record, got %q: %v", buf.String(), err)
+	}
+	if record.Level != "WARN" || record.Msg != "glob did not match any files" {
+		t.Errorf("record, expected a WARN about the glob, got %v %q", record.Level, record.Msg)
+	}
+	if record.Template != "index.template" || record.Pattern != "tpl/*.tmpl" || record.Base != "." {
+		t.Errorf("record fields, expected index.template, tpl/*.tmpl and ., got %q, %q and %q", record.Template, record.Pattern, record.Base)
+	}
+}
+
+func TestTemplateLoggerNil(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/index.template": {Data: []byte(`index`)},
+	}
+
+	var buf bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	_, err := template.New("index.template",
+		template.ParseFS(fsys, "tpl/index.template", "tpl/*.tmpl"),
+		template.Reload(template.ReloadNever),
+		template.Logger(nil),
+	)
+	if err != nil {
+		t.Fatalf("New, expected nil error, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("log, expected nothing to be logged, got %q", buf.String())
+	}
+}
+
+func TestTemplateLoggerQuiet(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/index.template": {Data: []byte(`index`)},
+	}
+
+	// Routine events are only logged at the debug level.
+	var buf bytes.Buffer
+	tpl := template.Must(
+		template.Must(
+			template.New("index.template",
+				template.ParseFS(fsys, "tpl/index.template"),
+				template.Reload(template.ReloadOnChange),
+				template.Logger(slog.New(slog.NewTextHandler(&buf, nil))),
+			)).ParseFiles())
+	ExecuteTemplateOrFail(t, tpl, nil, "index")
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing to be logged at the info level, got %q", buf.String())
+	}
+}
@@ -0,0 +1,122 @@
+package template_test
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplatePathNames(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"admin/index.template", `admin {{template "public/index" .}}`},
+			{"public/index.template", `public {{.}}`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("admin/index",
+				template.ResourceRoot("tpl"),
+				template.ParseGlob("**/index.template"),
+				template.PathNames(),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, "hello", "admin public hello")
+
+	var b bytes.Buffer
+	if err := tpl.ExecuteTemplate(&b, "public/index", "hello"); err != nil {
+		t.Fatalf("Got error executing template: %v", err)
+	}
+	if b.String() != "public hello" {
+		t.Errorf("expected: “public hello” got: “%v”", b.String())
+	}
+}
+
+func TestTemplateFileListAlias(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/parsefile.txt":         {Data: []byte("./admin/index.template as \"admin\"\n./public/index.template as \"public\"\n")},
+		"tpl/admin/index.template":  {Data: []byte(`admin {{template "public" .}}`)},
+		"tpl/public/index.template": {Data: []byte(`public {{.}}`)},
+	}
+
+	tpl := template.Must(
+		template.Must(
+			template.New("admin",
+				template.ParseFS(fsys),
+				template.ParseFileList("tpl/parsefile.txt"),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, "hello", "admin public hello")
+}
+
+func TestTemplatePathNamesNoResourceRoot(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"admin/index.template", `admin {{template "tpl/public/index" .}}`},
+			{"public/index.template", `public {{.}}`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("tpl/admin/index",
+				template.ParseGlob("tpl/*/index.template"),
+				template.PathNames(),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, "hello", "admin public hello")
+}
+
+func TestTemplatePathNamesParseFile(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"admin/index.template", `admin {{template "public/index" .}}`},
+			{"public/index.template", `public {{.}}`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("admin/index",
+				template.ResourceRoot("tpl"),
+				template.ParseFile("tpl/admin/index.template", "tpl/public/index.template"),
+				template.PathNames(),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, "hello", "admin public hello")
+}
+
+func TestTemplatePathNamesOutsideRoot(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"admin/index.template", `admin`},
+			{"public/index.template", `public`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.New("index",
+			template.ResourceRoot("tpl/admin"),
+			template.ParseFile("tpl/admin/index.template", "tpl/public/index.template"),
+			template.PathNames(),
+		))
+	if _, err := tpl.ParseFiles(); err == nil {
+		t.Fatalf("expected an error for a file outside the resource root")
+	}
+}
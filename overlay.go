@@ -0,0 +1,122 @@
+package template
+
+import (
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+)
+
+// ErrorOverlay will, while the template is reloading (its ReloadMode is not ReloadNever), replace the output of
+// Execute and ExecuteTemplate with an error page if reloading or executing the template fails. The error page shows
+// the error, where in which file it happened, and the sources of the template. Executions are rendered into a buffer
+// first, so a failing execution does not write partial output. The error is still returned.
+func ErrorOverlay() anOption {
+	return func(t *Template) error {
+		t.errorOverlay = true
+		return nil
+	}
+}
+
+// overlaid reports whether errors executing the template are shown with an error page.
+func (t *Template) overlaid() bool { return t.errorOverlay && t.mode != ReloadNever }
+
+// locateExecError returns the ParseError for the location of an error executing one of the files; any other error is
+// returned as is.
+func (t *Template) locateExecError(err error, files []parseFile) error {
+	var eerr texttemplate.ExecError
+	if !errors.As(err, &eerr) {
+		return err
+	}
+	// Errors executing a template start with the name the template was parsed in, the line, and the column.
+	for _, file := range files {
+		name, nerr := t.templateName(file)
+		if nerr != nil {
+			continue
+		}
+		location, ok := strings.CutPrefix(err.Error(), "template: "+name+":")
+		if !ok {
+			continue
+		}
+		line, rest, _ := strings.Cut(location, ":")
+		col, msg, _ := strings.Cut(rest, ": ")
+		pe := &ParseError{Err: err, name: name, msg: msg}
+		var aerr error
+		if pe.Line, aerr = strconv.Atoi(line); aerr != nil {
+			return err
+		}
+		pe.Col, _ = strconv.Atoi(col)
+		return t.locateParseError(pe, files)
+	}
+	return err
+}
+
+// errorPage is the data of the error page.
+type errorPage struct {
+	Name    string
+	Error   string
+	Parse   *ParseError
+	Pattern *PatternError
+	Sources []Source
+}
+
+// ErrorPage writes a self-contained HTML page describing err to w; if w is an http.ResponseWriter, the status is set
+// to 500. It is what the ErrorOverlay option shows, and can be used to show errors in the same way in an HTTP handler.
+func (t *Template) ErrorPage(w io.Writer, err error) error {
+	page := errorPage{Name: t.name, Error: err.Error()}
+	errors.As(err, &page.Parse)
+	errors.As(err, &page.Pattern)
+	t.parseLock.Lock()
+	for _, src := range t.parseFilesSources {
+		page.Sources = append(page.Sources, Source{Type: src.Type, File: src.File})
+	}
+	t.parseLock.Unlock()
+
+	if rw, ok := w.(http.ResponseWriter); ok {
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		rw.WriteHeader(http.StatusInternalServerError)
+	}
+	return errorPageTemplate.Execute(w, page)
+}
+
+var errorPageTemplate = template.Must(template.New("errorPage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Template error: {{.Name}}</title>
+<style>
+body { margin: 0; padding: 2em; font: 14px/1.5 sans-serif; background: #fdf2f2; color: #333; }
+h1 { margin-top: 0; font-size: 1.4em; color: #b71c1c; }
+h2 { font-size: 1em; margin: 1.5em 0 .5em; }
+.error { padding: 1em; background: #fff; border-left: 4px solid #b71c1c; white-space: pre-wrap; font-family: monospace; }
+.context { background: #fff; font-family: monospace; border-collapse: collapse; }
+.context td { padding: 0 .5em; white-space: pre; }
+.context .number { color: #999; text-align: right; }
+.context .current { background: #fde0e0; }
+ul { padding-left: 1.5em; font-family: monospace; }
+</style>
+</head>
+<body>
+<h1>Template “{{.Name}}” failed</h1>
+<div class="error">{{.Error}}</div>
+{{with .Parse}}
+<h2>{{.File}}{{if .Line}}:{{.Line}}{{if .Col}}:{{.Col}}{{end}}{{end}}{{with .Source.File}} <small>from {{$.Parse.Source}}</small>{{end}}</h2>
+{{if .Context}}<table class="context">
+{{range .Context}}<tr{{if eq .Line $.Parse.Line}} class="current"{{end}}><td class="number">{{.Line}}</td><td>{{.Text}}</td></tr>
+{{end}}</table>{{end}}
+{{end}}
+{{with .Pattern}}
+<h2>Pattern “{{.Pattern}}” in {{.Base}}{{with .FileList}} from file list {{.}}:{{$.Pattern.Line}}{{end}}</h2>
+{{end}}
+{{with .Sources}}
+<h2>Sources</h2>
+<ul>
+{{range .}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}
+</body>
+</html>
+`))
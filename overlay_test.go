@@ -0,0 +1,82 @@
+package template_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplateErrorOverlay(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/index.txt":      {Data: []byte("./index.template\n")},
+		"tpl/index.template": {Data: []byte("partial\n{{index . 5}}\n")},
+	}
+
+	tpl := template.Must(
+		template.Must(
+			template.New("index.template",
+				template.ParseFS(fsys),
+				template.ParseFileList("tpl/index.txt"),
+				template.Reload(template.ReloadOnChange),
+				template.ErrorOverlay(),
+				template.Logger(nil),
+			)).ParseFiles())
+
+	rec := httptest.NewRecorder()
+	if err := tpl.Execute(rec, []int{1}); err == nil {
+		t.Fatalf("expected an error executing the template")
+	}
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status, expected %v got %v", http.StatusInternalServerError, rec.Code)
+	}
+	page := rec.Body.String()
+	for _, expected := range []string{"<!DOCTYPE html>", "tpl/index.template:2:", "from file list tpl/index.txt:1", "<li>file list tpl/index.txt</li>"} {
+		if !strings.Contains(page, expected) {
+			t.Errorf("expected the error page to contain %q, got %v", expected, page)
+		}
+	}
+	if strings.HasPrefix(page, "partial") {
+		t.Errorf("expected no partial output, got %v", page)
+	}
+
+	// A broken reload shows the error page, rather than the last good set of templates.
+	fsys["tpl/index.template"] = &fstest.MapFile{Data: []byte("{{if .}}\n")}
+	var sb strings.Builder
+	if err := tpl.Execute(&sb, []int{1}); err == nil {
+		t.Fatalf("expected an error reloading the template")
+	}
+	if !strings.Contains(sb.String(), "unexpected EOF") {
+		t.Errorf("expected the error page to contain the reload error, got %v", sb.String())
+	}
+
+	fsys["tpl/index.template"] = &fstest.MapFile{Data: []byte("fixed")}
+	ExecuteTemplateOrFail(t, tpl, nil, "fixed")
+}
+
+func TestTemplateErrorOverlayReloadNever(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/index.template": {Data: []byte("partial\n{{index . 5}}\n")},
+	}
+
+	tpl := template.Must(
+		template.Must(
+			template.New("index.template",
+				template.ParseFS(fsys, "tpl/index.template"),
+				template.Reload(template.ReloadNever),
+				template.ErrorOverlay(),
+			)).ParseFiles())
+
+	var sb strings.Builder
+	if err := tpl.Execute(&sb, []int{1}); err == nil {
+		t.Fatalf("expected an error executing the template")
+	}
+	if sb.String() != "partial\n" {
+		t.Errorf("expected only the partial output, got %q", sb.String())
+	}
+}
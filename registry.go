@@ -0,0 +1,135 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+)
+
+// Registry is a set of templates, one for each page found under a root directory, that share the options of a base
+// config. Templates are only created, and parsed, the first time they are used; after that they are reloaded as set
+// by their ReloadMode. Pages are rendered as with the Pages option; so a page can name its layout, or use the
+// DefaultLayout of the base config.
+type Registry struct {
+	config BConfig
+	// root and patterns are where the pages are.
+	root     string
+	patterns []string
+	// finder is a template, that is never parsed, used to find the pages.
+	finder *Template
+
+	// lock guards templates; each page has a lock of its own for creating its template, so a slow page does not hold
+	// up the others.
+	lock      sync.Mutex
+	templates map[string]*registryPage
+}
+
+// registryPage is the template of a page in a registry, and the file of the page; tpl is nil until it is created.
+type registryPage struct {
+	lock sync.Mutex
+	tpl  *Template
+	file string
+}
+
+// NewRegistry returns a registry of the pages matching the patterns under root, which is relative to the resource root;
+// if no patterns are given all the files under root are pages. Pages are named as with the Pages option; such as
+// “pages/home”. Each page is a template created with the options of the base config, and the file of the page.
+func (bc BConfig) NewRegistry(root string, patterns ...string) (*Registry, error) {
+	finder, err := bc.NewTemplate("", Pages(root, patterns...))
+	if err != nil {
+		return nil, err
+	}
+	return &Registry{
+		config:    bc,
+		root:      root,
+		patterns:  patterns,
+		finder:    finder,
+		templates: make(map[string]*registryPage),
+	}, nil
+}
+
+// Names returns the names of the pages in the registry, in order. The root is searched every time; so pages added
+// since the registry was created are included.
+func (r *Registry) Names() ([]string, error) {
+	return r.finder.PageNames()
+}
+
+// Template returns the template of the page with the given name; creating and parsing it if this is the first time it
+// is asked for. The page is rendered with Render on the template, with the name of the page. If there is no such page
+// the error wraps ErrNoPage; unless the template never reloads, that is also the case once the file of a page that
+// has been asked for is removed.
+func (r *Registry) Template(name string) (*Template, error) {
+	r.lock.Lock()
+	page, ok := r.templates[name]
+	if !ok {
+		page = new(registryPage)
+		r.templates[name] = page
+	}
+	r.lock.Unlock()
+
+	page.lock.Lock()
+	defer page.lock.Unlock()
+	if page.tpl != nil {
+		if page.tpl.mode == ReloadNever {
+			return page.tpl, nil
+		}
+		_, err := fs.Stat(r.finder.fsys, page.file)
+		if err == nil {
+			return page.tpl, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		page.tpl = nil
+	}
+	tpl, file, err := r.newTemplate(name)
+	if err != nil {
+		// Failures are not kept; so the page is tried again, once fixed, the next time it is asked for.
+		r.lock.Lock()
+		if r.templates[name] == page {
+			delete(r.templates, name)
+		}
+		r.lock.Unlock()
+		return nil, err
+	}
+	page.tpl, page.file = tpl, file
+	return tpl, nil
+}
+
+// newTemplate creates and parses the template of the page with the given name, and returns it with the file of the
+// page.
+func (r *Registry) newTemplate(name string) (*Template, string, error) {
+	files, err := r.finder.pageFiles()
+	if err != nil {
+		return nil, "", err
+	}
+	file, ok := files[name]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %q", ErrNoPage, name)
+	}
+	tpl, err := r.config.NewTemplate(name, Pages(r.root, r.patterns...))
+	if err != nil {
+		return nil, "", err
+	}
+	// Without any files of its own, such as layouts, the page is parsed into the empty set of templates.
+	if tpl.hasSources() {
+		if _, err = tpl.ParseFiles(); err != nil {
+			return nil, "", err
+		}
+	}
+	if _, err = tpl.page(tpl.current.Load(), name, false); err != nil {
+		return nil, "", err
+	}
+	return tpl, file.File, nil
+}
+
+// Render executes the page with the given name, combined with its layout, with the data; see Template.
+func (r *Registry) Render(w io.Writer, name string, data interface{}) error {
+	tpl, err := r.Template(name)
+	if err != nil {
+		return err
+	}
+	return tpl.Render(w, name, data)
+}
@@ -0,0 +1,192 @@
+package template_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gdey/template"
+)
+
+func TestRegistry(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/partials/nav.template":      {Data: []byte(`{{define "nav"}}<nav>{{.}}</nav>{{end}}`)},
+		"tpl/pages/home.template":        {Data: []byte(`{{template "nav" .}}home`)},
+		"tpl/pages/admin/users.template": {Data: []byte(`users {{.}}`)},
+		"tpl/pages/broken.template":      {Data: []byte(`{{if .}}`)},
+	}
+
+	config := template.BaseConfig(
+		template.ParseFS(fsys, "tpl/partials/*.template"),
+		template.Reload(template.ReloadOnChange),
+		template.Logger(nil),
+	)
+	registry, err := config.NewRegistry("tpl", "pages/**/*.template")
+	if err != nil {
+		t.Fatalf("NewRegistry, expected nil error, got %v", err)
+	}
+
+	names, err := registry.Names()
+	if err != nil {
+		t.Fatalf("Names, expected nil error, got %v", err)
+	}
+	expectedNames := []string{"pages/admin/users", "pages/broken", "pages/home"}
+	if !reflect.DeepEqual(names, expectedNames) {
+		t.Errorf("Names, expected %v got %v", expectedNames, names)
+	}
+
+	tests := map[string]string{
+		"pages/home":        "<nav>x</nav>home",
+		"pages/admin/users": "users x",
+	}
+	for page, expected := range tests {
+		var sb strings.Builder
+		if err := registry.Render(&sb, page, "x"); err != nil {
+			t.Errorf("Render(%v), expected nil error, got %v", page, err)
+			continue
+		}
+		if sb.String() != expected {
+			t.Errorf("Render(%v), expected “%v” got “%v”", page, expected, sb.String())
+		}
+	}
+
+	// Templates are created once.
+	home, _ := registry.Template("pages/home")
+	if again, _ := registry.Template("pages/home"); again != home {
+		t.Errorf("Template, expected the same template for the same page")
+	}
+
+	var sb strings.Builder
+	if err := registry.Render(&sb, "pages/missing", nil); !errors.Is(err, template.ErrNoPage) {
+		t.Errorf("Render(pages/missing), expected ErrNoPage got %v", err)
+	}
+	var perr *template.ParseError
+	if err := registry.Render(&sb, "pages/broken", nil); !errors.As(err, &perr) {
+		t.Errorf("Render(pages/broken), expected a ParseError got %v", err)
+	}
+
+	// A page that failed is tried again, and pages are reloaded when they change.
+	fsys["tpl/pages/broken.template"] = &fstest.MapFile{Data: []byte(`fixed`)}
+	fsys["tpl/pages/home.template"] = &fstest.MapFile{Data: []byte(`new home`)}
+	for page, expected := range map[string]string{"pages/broken": "fixed", "pages/home": "new home"} {
+		sb.Reset()
+		if err := registry.Render(&sb, page, nil); err != nil || sb.String() != expected {
+			t.Errorf("Render(%v), expected “%v” got “%v” (%v)", page, expected, sb.String(), err)
+		}
+	}
+}
+
+func TestRegistryLayout(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/layouts/main.template":  {Data: []byte(`<main>{{template "content" .}}</main>`)},
+		"tpl/layouts/admin.template": {Data: []byte(`[admin]{{template "content" .}}`)},
+		"tpl/pages/home.template":    {Data: []byte(`{{define "content"}}home {{.}}{{end}}`)},
+		"tpl/pages/users.template":   {Data: []byte("{{/* layout: admin.template */}}\n{{define \"content\"}}users {{.}}{{end}}")},
+	}
+
+	config := template.BaseConfig(
+		template.ParseFS(fsys, "tpl/layouts/*.template"),
+		template.DefaultLayout("main.template"),
+		template.Reload(template.ReloadOnChange),
+		template.Logger(nil),
+	)
+	registry, err := config.NewRegistry("tpl", "pages/*.template")
+	if err != nil {
+		t.Fatalf("NewRegistry, expected nil error, got %v", err)
+	}
+
+	tests := map[string]string{
+		"pages/home":  "<main>home x</main>",
+		"pages/users": "[admin]users x",
+	}
+	for page, expected := range tests {
+		var sb strings.Builder
+		if err := registry.Render(&sb, page, "x"); err != nil {
+			t.Errorf("Render(%v), expected nil error, got %v", page, err)
+			continue
+		}
+		if sb.String() != expected {
+			t.Errorf("Render(%v), expected “%v” got “%v”", page, expected, sb.String())
+		}
+	}
+
+	// A page whose file is removed is no longer served.
+	delete(fsys, "tpl/pages/users.template")
+	var sb strings.Builder
+	if err := registry.Render(&sb, "pages/users", "x"); !errors.Is(err, template.ErrNoPage) {
+		t.Errorf("Render(pages/users), expected ErrNoPage got %v", err)
+	}
+	if _, err := registry.Template("pages/users"); !errors.Is(err, template.ErrNoPage) {
+		t.Errorf("Template(pages/users), expected ErrNoPage got %v", err)
+	}
+}
+
+func TestRegistryNoLayout(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"pages/home.template", `home {{.}}`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	// The base config names no files; the pages are rendered by themselves.
+	for _, mode := range []template.ReloadMode{template.ReloadNever, template.ReloadOnChange} {
+		config := template.BaseConfig(
+			template.ResourceRoot("tpl"),
+			template.Reload(mode),
+		)
+		registry, err := config.NewRegistry("pages")
+		if err != nil {
+			t.Fatalf("NewRegistry, expected nil error, got %v", err)
+		}
+		var sb strings.Builder
+		if err := registry.Render(&sb, "home", "x"); err != nil || sb.String() != "home x" {
+			t.Errorf("Render(home), expected “home x” got “%v” (%v)", sb.String(), err)
+		}
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"tpl/pages/a.template": {Data: []byte(`a {{.}}`)},
+		"tpl/pages/b.template": {Data: []byte(`b {{.}}`)},
+		"tpl/pages/c.template": {Data: []byte(`c {{.}}`)},
+	}
+
+	config := template.BaseConfig(
+		template.ParseFS(fsys),
+		template.Reload(template.ReloadOnChange),
+	)
+	registry, err := config.NewRegistry("tpl", "pages/*.template")
+	if err != nil {
+		t.Fatalf("NewRegistry, expected nil error, got %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 30; i++ {
+		page := []string{"pages/a", "pages/b", "pages/c", "pages/missing"}[i%4]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var sb strings.Builder
+			err := registry.Render(&sb, page, "x")
+			switch {
+			case page == "pages/missing":
+				if !errors.Is(err, template.ErrNoPage) {
+					t.Errorf("Render(%v), expected ErrNoPage got %v", page, err)
+				}
+			case err != nil || sb.String() != page[len("pages/"):]+" x":
+				t.Errorf("Render(%v), got “%v” (%v)", page, sb.String(), err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,327 @@
+package template
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// ReloadMode controls when a template reparses its files and rebuilds its build files.
+type ReloadMode uint
+
+const (
+	// ReloadNever parses the files once; build files are only rebuilt if they are missing from the dist directory.
+	ReloadNever = ReloadMode(iota)
+	// ReloadAlways reparses all the files on every Execute and rebuilds the build files every time they are asked for.
+	ReloadAlways
+	// ReloadOnChange reparses all the files on Execute, and rebuilds a build file when it is asked for, only if any of
+	// the files (or the files matched by the globs and file lists) have changed since they were last parsed or built.
+	ReloadOnChange
+)
+
+func (mode ReloadMode) String() string {
+	switch mode {
+	case ReloadNever:
+		return "never"
+	case ReloadAlways:
+		return "always"
+	case ReloadOnChange:
+		return "on change"
+	default:
+		return fmt.Sprintf("ReloadMode(%d)", uint(mode))
+	}
+}
+
+// Reload sets the ReloadMode of the template. Without this option the template uses ReloadOnChange when built with the
+// debug tag and ReloadNever otherwise.
+func Reload(mode ReloadMode) anOption {
+	return func(t *Template) error {
+		t.mode = mode
+		return nil
+	}
+}
+
+// addSourceFile will add the source of the file.
+func addSourceFile(t *Template, typ sourceType, file string) {
+	t.parseLock.Lock()
+	defer t.parseLock.Unlock()
+	t.parseFilesSources = append(t.parseFilesSources, parseFileSources{
+		Type: typ,
+		File: file,
+	})
+}
+
+// genParseFileList will go through the data-structure and generate the file list to parse. Globs that do not match any
+// files are warned about to logger.
+func genParseFileList(t *Template, logger *slog.Logger) (parseFiles []parseFile, err error) {
+	t.parseLock.Lock()
+	sources := append([]parseFileSources(nil), t.parseFilesSources...)
+	t.parseLock.Unlock()
+
+	for _, filesrc := range sources {
+		var files []parseFile
+		switch filesrc.Type {
+		case SrcGlobFile:
+			files, err = parseGlob(t, filesrc.File, logger)
+		case SrcFileList:
+			files, err = parseFileList(t, filesrc.File, logger)
+		case SrcParseFile:
+			files = parseFilesFor(Source{Type: SrcParseFile, File: filesrc.File}, filesrc.File)
+		}
+		if err != nil {
+			return nil, err
+		}
+		parseFiles = append(parseFiles, files...)
+	}
+	return parseFiles, nil
+}
+
+// templateSet is a parsed set of templates, ready to be executed.
+type templateSet struct {
+	tmpl *template.Template
+	// pristine is a clone of tmpl that is never executed; so it can be cloned for executions that need their own
+	// helpers, such as ExecuteContext.
+	pristine *template.Template
+	// usesContext is set if the templates use helpers that ExecuteContext binds to its context.
+	usesContext bool
+	// bound are copies of pristine with the helpers bound to a context; see bind.
+	bound *sync.Pool
+}
+
+// newTemplateSet returns the set of templates of tmpl, which must not have been executed.
+func (t *Template) newTemplateSet(tmpl *template.Template) (templateSet, error) {
+	pristine, err := tmpl.Clone()
+	if err != nil {
+		return templateSet{}, err
+	}
+	return templateSet{tmpl: tmpl, pristine: pristine, usesContext: t.usesContext(tmpl), bound: new(sync.Pool)}, nil
+}
+
+// snapshot is a fully parsed set of templates, along with the files that went into it. A snapshot is never modified
+// once it has been published.
+type snapshot struct {
+	templateSet
+	// files are the files that were parsed, in order.
+	files []parseFile
+	// stamps are the stamps of the files when they were parsed; nil for the empty set of templates a new template
+	// starts out with.
+	stamps fileStamps
+}
+
+// parsed reports whether the snapshot was built by parsing files; rather than being the empty starting set.
+func (snap *snapshot) parsed() bool { return snap.stamps != nil }
+
+// build parses the files into a new set of templates, and validates it. The published set of templates is not
+// touched.
+func (t *Template) build(files []parseFile, stamps fileStamps) (*snapshot, error) {
+	t.fullLock()
+	tmpl := t.newTemplate()
+	t.fullUnlock()
+
+	if err := t.parseFilesInto(tmpl, files...); err != nil {
+		return nil, err
+	}
+	// With pages, the templates are only complete once combined with a page; so they may invoke the templates the
+	// pages define, and each page is validated again when it is parsed.
+	var err error
+	switch {
+	case !t.strict:
+	case len(t.pageSources) == 0:
+		err = validate(tmpl)
+	default:
+		err = validateWith(tmpl, t.pageTemplates())
+	}
+	if err != nil {
+		return nil, t.locateParseError(err, files)
+	}
+	set, err := t.newTemplateSet(tmpl)
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot{
+		templateSet: set,
+		files:       files,
+		stamps:      stamps,
+	}, nil
+}
+
+// publish makes snap the current set of templates.
+func (t *Template) publish(snap *snapshot) {
+	t.fullLock()
+	t.Template = snap.tmpl
+	t.parseFiles = snap.files
+	t.fullUnlock()
+	t.current.Store(snap)
+}
+
+// reload will generate the list of files and, if force is set or any of the files have changed, build and publish
+// a new set of templates. It returns the current set of templates. If the new set of templates fails to build the
+// current set is left in place, and the error is recorded and reported to the OnReloadError callback.
+func (t *Template) reload(force bool) (*snapshot, error) {
+	snap, retried, err := t.rebuild(force)
+	t.reportReloadError(err, !retried)
+	return snap, err
+}
+
+// reportReloadError records the error of a reload, which is nil if it succeeded; if notify is set a failure is also
+// logged and passed to the OnReloadError callback.
+func (t *Template) reportReloadError(err error, notify bool) {
+	t.errLock.Lock()
+	t.reloadErr = err
+	t.errLock.Unlock()
+	if err != nil && notify {
+		t.log().Error("reloading templates failed", "error", err)
+		if t.onReloadError != nil {
+			t.onReloadError(t, err)
+		}
+	}
+}
+
+// rebuild does the work for reload. Unless forced, a set of files that failed to build is not rebuilt until one of
+// the files changes; the previous error is returned instead and retried is set.
+func (t *Template) rebuild(force bool) (snap *snapshot, retried bool, err error) {
+	t.reloadLock.Lock()
+	defer t.reloadLock.Unlock()
+
+	files, err := genParseFileList(t, t.log())
+	if err != nil {
+		return nil, false, err
+	}
+	stamps := stampFiles(t.fsys, filenamesOf(files)...)
+	if !force {
+		if cur := t.current.Load(); cur.parsed() && cur.stamps.equal(stamps) && equalParseFiles(cur.files, files) {
+			return cur, false, nil
+		}
+		if t.failed != nil && t.failed.stamps.equal(stamps) && equalParseFiles(t.failed.files, files) {
+			return nil, true, t.ReloadError()
+		}
+	}
+	t.failed = nil
+	if snap, err = t.build(files, stamps); err != nil {
+		t.failed = &snapshot{files: files, stamps: stamps}
+		return nil, false, err
+	}
+	t.publish(snap)
+	t.log().Debug("reloaded templates", "files", len(files))
+	return snap, false, nil
+}
+
+// snapshot returns the set of templates to use for an execution; depending on the ReloadMode of the template, the
+// files are reparsed first. If reparsing fails, the last good set of templates is returned; the error is only returned
+// if there is no good set of templates to fall back on. A template with no files to parse, such as one that only
+// renders pages, is never reparsed.
+func (t *Template) snapshot() (*snapshot, error) {
+	var (
+		snap *snapshot
+		err  error
+	)
+	switch {
+	case !t.hasSources():
+		return t.current.Load(), nil
+	case t.mode == ReloadAlways:
+		snap, err = t.reload(true)
+	case t.mode == ReloadOnChange:
+		snap, err = t.reload(false)
+	default:
+		return t.current.Load(), nil
+	}
+	if err != nil {
+		if cur := t.current.Load(); cur.parsed() {
+			return cur, nil
+		}
+		return nil, err
+	}
+	return snap, nil
+}
+
+// hasSources reports whether any files, globs, or file lists have been given to parse.
+func (t *Template) hasSources() bool {
+	t.parseLock.Lock()
+	defer t.parseLock.Unlock()
+	return len(t.parseFilesSources) != 0
+}
+
+// ParseFiles will parse the files that have been build up
+func (t *Template) ParseFiles() (*Template, error) {
+	_, err := t.reload(true)
+	return t, err
+}
+
+// Reload will reparse all the files, regardless of the ReloadMode of the template, and rebuild the build files the
+// template has been asked for so far. The new set of templates is only used once it has been completely parsed, and
+// with the Strict option validated; if there is an error, the template keeps using the last good set of templates and
+// the error is returned. Errors rebuilding the build files are reported, and returned, like errors reparsing the files.
+func (t *Template) Reload() error {
+	if _, err := t.reload(true); err != nil {
+		return err
+	}
+	err := t.rebuildFiles()
+	if err != nil {
+		t.reportReloadError(err, true)
+	}
+	return err
+}
+
+// ReloadError returns the error of the last reload, or nil if it succeeded.
+func (t *Template) ReloadError() error {
+	t.errLock.Lock()
+	defer t.errLock.Unlock()
+	return t.reloadErr
+}
+
+// OnReloadError sets a function that is called, with the error, every time reloading the template fails.
+func OnReloadError(fn func(t *Template, err error)) anOption {
+	return func(t *Template) error {
+		t.onReloadError = fn
+		return nil
+	}
+}
+
+// Execute will execute the template with the given data. Depending on the ReloadMode of the template, the files are
+// reparsed first. Executions work on their own set of templates, so it is safe to call Execute while the template is
+// being reloaded.
+func (t *Template) Execute(w io.Writer, data interface{}) error {
+	return t.execute(w, t.buffered, func(snap *snapshot, w io.Writer) error {
+		return snap.tmpl.Execute(w, data)
+	})
+}
+
+// ExecuteTemplate will execute the template associated with t that has the given name with the given data. Depending
+// on the ReloadMode of the template, the files are reparsed first.
+func (t *Template) ExecuteTemplate(w io.Writer, name string, data interface{}) error {
+	return t.execute(w, t.buffered, func(snap *snapshot, w io.Writer) error {
+		return snap.tmpl.ExecuteTemplate(w, name, data)
+	})
+}
+
+// Lookup returns the template with the given name that is associated with t, or nil if there is no such template.
+// Depending on the ReloadMode of the template, the files are reparsed first; any error reparsing is ignored.
+func (t *Template) Lookup(name string) *template.Template {
+	snap, err := t.snapshot()
+	if err != nil {
+		snap = t.current.Load()
+	}
+	return snap.tmpl.Lookup(name)
+}
+
+// Templates returns a slice of the templates associated with t, including t itself. Depending on the ReloadMode of
+// the template, the files are reparsed first; any error reparsing is ignored.
+func (t *Template) Templates() []*template.Template {
+	snap, err := t.snapshot()
+	if err != nil {
+		snap = t.current.Load()
+	}
+	return snap.tmpl.Templates()
+}
+
+// DefinedTemplates returns a string listing the defined templates, prefixed by the string "; defined templates are: ".
+// Depending on the ReloadMode of the template, the files are reparsed first; any error reparsing is ignored.
+func (t *Template) DefinedTemplates() string {
+	snap, err := t.snapshot()
+	if err != nil {
+		snap = t.current.Load()
+	}
+	return snap.tmpl.DefinedTemplates()
+}
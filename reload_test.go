@@ -0,0 +1,327 @@
+package template_test
+
+import (
+	"bytes"
+	"errors"
+	htmltemplate "html/template"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplateReloadMode(t *testing.T) {
+
+	tests := []struct {
+		mode     template.ReloadMode
+		expected string
+	}{
+		{mode: template.ReloadNever, expected: "This is a template! hello"},
+		{mode: template.ReloadAlways, expected: "This is a template after! hello"},
+		{mode: template.ReloadOnChange, expected: "This is a template after! hello"},
+	}
+
+	for _, test := range tests {
+		fixture := FileList{
+			BaseDir: "tpl",
+			Files: []FileType{
+				{"parsefile.template", `This is a template! {{.}}`},
+			},
+		}
+		fixture.CreateFilesOFail(t)
+
+		tpl := template.Must(
+			template.Must(
+				template.New("parsefile.template",
+					template.ParseFile("tpl/parsefile.template"),
+					template.DistRoot("tpl/dist"),
+					template.Reload(test.mode),
+				)).ParseFiles())
+
+		ExecuteTemplateOrFail(t, tpl, "hello", "This is a template! hello")
+		fixture.SetFile("parsefile.template", `This is a template after! {{.}}`).CreateFileOrFail(t, "parsefile.template")
+		ExecuteTemplateOrFail(t, tpl, "hello", test.expected)
+		fixture.RemoveAll()
+	}
+}
+
+func TestTemplateBuildLinkJSReloadMode(t *testing.T) {
+
+	tests := []struct {
+		mode     template.ReloadMode
+		expected string
+	}{
+		{mode: template.ReloadNever, expected: `<script type="text/javascript" src="jsbuild-cbe88841a0d1c699592e2a61e3ffa7c33b61a4f7.js"></script>`},
+		{mode: template.ReloadAlways, expected: `<script type="text/javascript" src="jsbuild-5cf0442672da09dfce402e2f3adbe5bf0139d0ec.js"></script>`},
+		{mode: template.ReloadOnChange, expected: `<script type="text/javascript" src="jsbuild-5cf0442672da09dfce402e2f3adbe5bf0139d0ec.js"></script>`},
+	}
+
+	for _, test := range tests {
+		fixture := FileList{
+			BaseDir: "tpl",
+			Files: []FileType{
+				{"views/1.js", `alert(1);`},
+				{"parsefile.template", "{{buildLinkToJSFiles `tpl/views/1.js`}}"},
+			},
+		}
+		fixture.CreateFilesOFail(t)
+
+		tpl := template.Must(
+			template.Must(
+				template.New("parsefile.template",
+					template.ParseFile("tpl/parsefile.template"),
+					template.DistRoot("tpl/dist"),
+					template.Reload(test.mode),
+				)).ParseFiles())
+
+		ExecuteTemplateOrFail(t, tpl, "hello", `<script type="text/javascript" src="jsbuild-cbe88841a0d1c699592e2a61e3ffa7c33b61a4f7.js"></script>`)
+		fixture.SetFile("views/1.js", "alert(2);").CreateFileOrFail(t, "views/1.js")
+		ExecuteTemplateOrFail(t, tpl, "hello", test.expected)
+		fixture.RemoveAll()
+	}
+}
+
+func TestTemplateReloadOnChange(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.template", `{{template "a"}}{{block "b" .}}{{end}}`},
+			{"partials/a.template", `{{define "a"}}A{{end}}`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFile("tpl/parsefile.template"),
+				template.ParseGlob("tpl/partials/*.template"),
+				template.Reload(template.ReloadOnChange),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, nil, "A")
+	parsed := tpl.Template
+	ExecuteTemplateOrFail(t, tpl, nil, "A")
+	if parsed != tpl.Template {
+		t.Errorf("expected the templates not to be reparsed when no files changed")
+	}
+
+	// A new file matching the glob should cause a reparse.
+	fixture.SetFile("partials/b.template", `{{define "b"}}B{{end}}`).CreateFileOrFail(t, "partials/b.template")
+	ExecuteTemplateOrFail(t, tpl, nil, "AB")
+}
+
+func TestTemplateReloadExecuteTemplate(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.template", `{{define "page"}}Page! {{.}}{{end}}`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFile("tpl/parsefile.template"),
+				template.Reload(template.ReloadOnChange),
+			)).ParseFiles())
+
+	var b bytes.Buffer
+	if err := tpl.ExecuteTemplate(&b, "page", "hello"); err != nil {
+		t.Fatalf("Got error executing template: %v", err)
+	}
+	if b.String() != "Page! hello" {
+		t.Fatalf("expected: “Page! hello” got: “%v”", b.String())
+	}
+
+	fixture.SetFile("parsefile.template", `{{define "page"}}Page after! {{.}}{{end}}{{define "other"}}{{end}}`).CreateFileOrFail(t, "parsefile.template")
+	if tpl.Lookup("other") == nil {
+		t.Fatalf("expected Lookup to find the newly defined template")
+	}
+	b.Reset()
+	if err := tpl.ExecuteTemplate(&b, "page", "hello"); err != nil {
+		t.Fatalf("Got error executing template: %v", err)
+	}
+	if b.String() != "Page after! hello" {
+		t.Fatalf("expected: “Page after! hello” got: “%v”", b.String())
+	}
+}
+
+func TestTemplateReloadKeepsConfig(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.template", `[[shout .name]]`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.New("parsefile.template",
+			template.ParseFile("tpl/parsefile.template"),
+			template.Delims("[[", "]]"),
+			template.MissingKey("error"),
+			template.Reload(template.ReloadOnChange),
+		))
+	tpl = template.Must(
+		tpl.Funcs(htmltemplate.FuncMap{"shout": strings.ToUpper}).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, map[string]string{"name": "hello"}, "HELLO")
+	fixture.SetFile("parsefile.template", `[[shout .name]]!`).CreateFileOrFail(t, "parsefile.template")
+	ExecuteTemplateOrFail(t, tpl, map[string]string{"name": "hello"}, "HELLO!")
+
+	var b bytes.Buffer
+	if err := tpl.Execute(&b, map[string]string{}); err == nil {
+		t.Errorf("expected a missingkey error after reloading, got: “%v”", b.String())
+	}
+}
+
+func TestTemplateReloadConcurrent(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.template", `{{template "include"}}`},
+			{"include.template", `{{define "include"}}0{{end}}`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFile("tpl/parsefile.template", "tpl/include.template"),
+				template.Reload(template.ReloadAlways),
+			)).ParseFiles())
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8*20)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				var b bytes.Buffer
+				if err := tpl.Execute(&b, nil); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Got error executing template concurrently: %v", err)
+	}
+}
+
+func TestTemplateReloadKeepsLastGood(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.template", `This is a template! {{.}}`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	var reported []error
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFile("tpl/parsefile.template"),
+				template.Reload(template.ReloadOnChange),
+				template.Strict(),
+				template.OnReloadError(func(_ *template.Template, err error) {
+					reported = append(reported, err)
+				}),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, "hello", "This is a template! hello")
+
+	fixture.SetFile("parsefile.template", `This is a broken template! {{.}`).CreateFileOrFail(t, "parsefile.template")
+	ExecuteTemplateOrFail(t, tpl, "hello", "This is a template! hello")
+	ExecuteTemplateOrFail(t, tpl, "hello", "This is a template! hello")
+	if tpl.ReloadError() == nil {
+		t.Errorf("expected a reload error")
+	}
+	if len(reported) != 1 {
+		t.Errorf("expected the reload error to be reported once, got %v", len(reported))
+	}
+
+	fixture.SetFile("parsefile.template", `{{template "missing"}}`).CreateFileOrFail(t, "parsefile.template")
+	if err := tpl.Reload(); err == nil {
+		t.Errorf("expected Reload to fail validation for a missing template")
+	}
+	ExecuteTemplateOrFail(t, tpl, "hello", "This is a template! hello")
+
+	fixture.SetFile("parsefile.template", `This is a template after! {{.}}`).CreateFileOrFail(t, "parsefile.template")
+	ExecuteTemplateOrFail(t, tpl, "hello", "This is a template after! hello")
+	if err := tpl.ReloadError(); err != nil {
+		t.Errorf("expected no reload error, got %v", err)
+	}
+}
+
+func TestTemplateExplicitReload(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.template", `This is a template! {{.}}`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFile("tpl/parsefile.template"),
+				template.Reload(template.ReloadNever),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, "hello", "This is a template! hello")
+	fixture.SetFile("parsefile.template", `This is a template after! {{.}}`).CreateFileOrFail(t, "parsefile.template")
+	ExecuteTemplateOrFail(t, tpl, "hello", "This is a template! hello")
+	if err := tpl.Reload(); err != nil {
+		t.Fatalf("Got error reloading template: %v", err)
+	}
+	ExecuteTemplateOrFail(t, tpl, "hello", "This is a template after! hello")
+}
+
+func TestTemplateUndefinedTemplate(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.template", `<main>{{template "content" .}}</main>`},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	// As with html/template, a set can invoke a template that is only defined later.
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFile("tpl/parsefile.template"),
+				template.Reload(template.ReloadNever),
+			)).ParseFiles())
+	htmltemplate.Must(tpl.New("content").Parse(`content {{.}}`))
+	ExecuteTemplateOrFail(t, tpl, "x", "<main>content x</main>")
+
+	// With Strict, it is an error to parse such a set.
+	tpl = template.Must(
+		template.New("parsefile.template",
+			template.ParseFile("tpl/parsefile.template"),
+			template.Reload(template.ReloadNever),
+			template.Strict(),
+		))
+	var perr *template.ParseError
+	if _, err := tpl.ParseFiles(); !errors.As(err, &perr) || perr.File != "tpl/parsefile.template" {
+		t.Errorf("ParseFiles, expected a ParseError for tpl/parsefile.template got %v", err)
+	}
+}
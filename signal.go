@@ -0,0 +1,79 @@
+package template
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Reloader reloads a set of registered templates every time the process receives a signal. This allows a process,
+// even one using ReloadNever, to pick up changes to the templates and assets without being restarted.
+type Reloader struct {
+	lock sync.Mutex
+	tpls []*Template
+
+	signals chan os.Signal
+	done    chan struct{}
+	stop    sync.Once
+}
+
+// ReloadOnSignal returns a Reloader that reloads the registered templates every time the process receives one of
+// the given signals; SIGHUP if no signals are given. Errors are reported through the OnReloadError callback and the
+// ReloadError accessor of each template.
+func ReloadOnSignal(sigs ...os.Signal) *Reloader {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	r := &Reloader{
+		signals: make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+	}
+	signal.Notify(r.signals, sigs...)
+	go func() {
+		for {
+			select {
+			case <-r.signals:
+				// Every template reports its own errors; through its logger, its OnReloadError callback, and
+				// ReloadError.
+				_ = r.ReloadAll()
+			case <-r.done:
+				return
+			}
+		}
+	}()
+	return r
+}
+
+// Register adds the templates to the set of templates that are reloaded.
+func (r *Reloader) Register(tpls ...*Template) *Reloader {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.tpls = append(r.tpls, tpls...)
+	return r
+}
+
+// ReloadAll will reload all the registered templates, and rebuild their build files, right away. It returns the
+// errors of all the templates that failed to reload.
+func (r *Reloader) ReloadAll() error {
+	r.lock.Lock()
+	tpls := append([]*Template(nil), r.tpls...)
+	r.lock.Unlock()
+
+	var errs []error
+	for _, tpl := range tpls {
+		if err := tpl.Reload(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stop stops the Reloader from listening for signals.
+func (r *Reloader) Stop() {
+	r.stop.Do(func() {
+		signal.Stop(r.signals)
+		close(r.done)
+	})
+}
@@ -0,0 +1,122 @@
+package template_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gdey/template"
+)
+
+func TestTemplateReloaderReloadAll(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"views/1.js", `alert(1);`},
+			{"parsefile.template", "{{buildLinkToJSFiles `tpl/views/1.js`}}"},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFile("tpl/parsefile.template"),
+				template.DistRoot("tpl/dist"),
+				template.Reload(template.ReloadNever),
+			)).ParseFiles())
+
+	reloader := template.ReloadOnSignal().Register(tpl)
+	defer reloader.Stop()
+
+	ExecuteTemplateOrFail(t, tpl, nil, `<script type="text/javascript" src="jsbuild-cbe88841a0d1c699592e2a61e3ffa7c33b61a4f7.js"></script>`)
+	fixture.SetFile("views/1.js", "alert(2);").CreateFileOrFail(t, "views/1.js")
+	fixture.SetFile("parsefile.template", "reloaded: {{buildLinkToJSFiles `tpl/views/1.js`}}").CreateFileOrFail(t, "parsefile.template")
+	ExecuteTemplateOrFail(t, tpl, nil, `<script type="text/javascript" src="jsbuild-cbe88841a0d1c699592e2a61e3ffa7c33b61a4f7.js"></script>`)
+
+	if err := reloader.ReloadAll(); err != nil {
+		t.Fatalf("Got error reloading templates: %v", err)
+	}
+	ExecuteTemplateOrFail(t, tpl, nil, `reloaded: <script type="text/javascript" src="jsbuild-5cf0442672da09dfce402e2f3adbe5bf0139d0ec.js"></script>`)
+}
+
+func TestTemplateReloaderSignal(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"parsefile.template", "before"},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFile("tpl/parsefile.template"),
+				template.Reload(template.ReloadNever),
+			)).ParseFiles())
+
+	reloader := template.ReloadOnSignal().Register(tpl)
+	defer reloader.Stop()
+
+	fixture.SetFile("parsefile.template", "after").CreateFileOrFail(t, "parsefile.template")
+	ExecuteTemplateOrFail(t, tpl, nil, "before")
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("Got error sending SIGHUP: %v", err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		var sb strings.Builder
+		if err := tpl.Execute(&sb, nil); err != nil {
+			t.Fatalf("Got error Executing template: %v", err)
+		}
+		if sb.String() == "after" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the template to be reloaded on SIGHUP, got “%v”", sb.String())
+		}
+	}
+}
+
+func TestTemplateReloadBuildError(t *testing.T) {
+
+	fixture := FileList{
+		BaseDir: "tpl",
+		Files: []FileType{
+			{"views/1.js", `alert(1);`},
+			{"parsefile.template", "{{buildJSFiles `tpl/views/1.js`}}"},
+		},
+	}
+	defer fixture.CreateFilesOFail(t).RemoveAll()
+
+	var reported []error
+	tpl := template.Must(
+		template.Must(
+			template.New("parsefile.template",
+				template.ParseFile("tpl/parsefile.template"),
+				template.DistRoot("tpl/dist"),
+				template.Reload(template.ReloadNever),
+				template.Strict(),
+				template.Logger(nil),
+				template.OnReloadError(func(_ *template.Template, err error) { reported = append(reported, err) }),
+			)).ParseFiles())
+
+	ExecuteTemplateOrFail(t, tpl, nil, "jsbuild-cbe88841a0d1c699592e2a61e3ffa7c33b61a4f7.js")
+	os.Remove("tpl/views/1.js")
+
+	err := tpl.Reload()
+	if !errors.Is(err, template.ErrNoMatch) {
+		t.Fatalf("Reload, expected ErrNoMatch got %v", err)
+	}
+	if !errors.Is(tpl.ReloadError(), template.ErrNoMatch) {
+		t.Errorf("ReloadError, expected ErrNoMatch got %v", tpl.ReloadError())
+	}
+	if len(reported) != 1 || !errors.Is(reported[0], template.ErrNoMatch) {
+		t.Errorf("OnReloadError, expected to be called once with ErrNoMatch, got %v", reported)
+	}
+}
@@ -1,14 +1,18 @@
 package template
 
 import (
-	"bufio"
+	"context"
 	"fmt"
 	"html/template"
-	"log"
+	"io/fs"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gdey/template/helpers"
 )
@@ -30,6 +34,69 @@ type parseFileSources struct {
 	File string
 }
 
+// Source is where a file to parse was named: a line of a file list, a glob, or the file itself.
+type Source struct {
+	Type sourceType
+	// File is the file list, the glob, or the file given to ParseFile.
+	File string
+	// Line is the line of the file list that named the file; zero if the file was not named by a line of a file list.
+	Line int
+}
+
+func (src Source) String() string {
+	switch src.Type {
+	case SrcFileList:
+		if src.Line == 0 {
+			return fmt.Sprintf("file list %v", src.File)
+		}
+		return fmt.Sprintf("file list %v:%v", src.File, src.Line)
+	case SrcGlobFile:
+		return fmt.Sprintf("glob %v", src.File)
+	default:
+		return fmt.Sprintf("file %v", src.File)
+	}
+}
+
+// parseFile is a file to parse, and the name of the template to parse it into.
+type parseFile struct {
+	File string
+	// Name is the name given to the file with “as” in a file list; if empty the name is derived from the file.
+	Name string
+	// Source is where the file was named.
+	Source Source
+}
+
+// parseFilesFor returns the files to parse for the filenames, all named by src.
+func parseFilesFor(src Source, filenames ...string) []parseFile {
+	files := make([]parseFile, 0, len(filenames))
+	for _, filename := range filenames {
+		files = append(files, parseFile{File: filename, Source: src})
+	}
+	return files
+}
+
+// filenamesOf returns the filenames of the files to parse.
+func filenamesOf(files []parseFile) []string {
+	filenames := make([]string, 0, len(files))
+	for _, file := range files {
+		filenames = append(filenames, file.File)
+	}
+	return filenames
+}
+
+// equalParseFiles reports whether both lists name the same files, with the same names, in the same order.
+func equalParseFiles(a, b []parseFile) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // DefaultBase is the default base directory for finding resources.
 var DefaultBase string
 
@@ -45,7 +112,7 @@ func init() {
 
 	var err error
 	if DefaultBase, err = os.Getwd(); err != nil {
-		log.Println("Unable to get current working directory, defaulting to exec dir for base.")
+		slog.Warn("unable to get current working directory, defaulting to exec dir for base", "error", err)
 		DefaultBase = execDir()
 	}
 
@@ -53,6 +120,8 @@ func init() {
 
 // Template is the main template object.
 type Template struct {
+	// Template is the latest set of templates that was built. When the template is being reloaded, use the methods
+	// of Template instead of the ones of the embedded html/template, as they work on a consistent set of templates.
 	*template.Template
 
 	// Initial name of the Template.
@@ -65,8 +134,60 @@ type Template struct {
 	// The base url
 	root string
 
+	// mode controls when the files are reparsed and the build files rebuilt.
+	mode ReloadMode
+
+	// fsys is the file system templates and file lists are read from.
+	fsys fs.FS
+	// assetFS is the file system the assets for the build helpers are read from.
+	assetFS fs.FS
+
 	// helpers are the Helpers that the user is adding
 	helpers template.FuncMap
+	// userHelpers are the names of the helpers set with the Helpers option or Funcs; ExecuteContext does not replace
+	// them with helpers bound to its context.
+	userHelpers map[string]struct{}
+
+	// The action delimiters; empty means the html/template defaults.
+	leftDelim  string
+	rightDelim string
+	// The html/template options, such as "missingkey=error".
+	options []string
+
+	// vars are the variables available to file lists.
+	vars map[string]string
+
+	// pathNames names templates by their path, rather then their base name.
+	pathNames bool
+	// strictDefines makes defining a template more then once an error.
+	strictDefines bool
+	// strict makes patterns that do not resolve to readable files an error.
+	strict bool
+
+	// buffered renders executions into a buffer, so failing executions do not write partial output.
+	buffered bool
+	// pageSources are where the pages are, and defaultLayout the layout they are rendered with.
+	pageSources   []pageSource
+	defaultLayout string
+	// pagesLock guards the pages parsed for the snapshot pagesFor.
+	pagesLock sync.Mutex
+	pages     map[pageKey]*page
+	pagesFor  *snapshot
+
+	// fragmentHeader and fragmentParam are the header and query parameter a request asks for a fragment with.
+	fragmentHeader string
+	fragmentParam  string
+	// errorTemplate is the template the handlers execute when a request fails.
+	errorTemplate string
+	// errorOverlay replaces the output of a failing execution with an error page, while reloading.
+	errorOverlay bool
+
+	// liveReloadURL is the URL of the LiveReloadHandler, and liveReloadInterval how often it checks for changes.
+	liveReloadURL      string
+	liveReloadInterval time.Duration
+
+	// logger is where warnings and notices are written, with the name of the template attached.
+	logger *slog.Logger
 
 	// Lock for parseFileSouces and parseFiles
 	parseLock sync.Mutex
@@ -74,12 +195,31 @@ type Template struct {
 	parseFilesSources []parseFileSources
 
 	// This is a list file to parse.
-	parseFiles []string
+	parseFiles []parseFile
+
+	// reloadLock makes sure only one set of templates is being built at a time.
+	reloadLock sync.Mutex
+	// failed holds the files, and their stamps, of the last set of templates that failed to build.
+	failed *snapshot
+	// current is the latest set of templates that was built. It is never modified once published, so it can be
+	// executed without holding any locks.
+	current atomic.Pointer[snapshot]
+
+	// Lock for reloadErr
+	errLock sync.Mutex
+	// reloadErr is the error of the last reload.
+	reloadErr error
+	// onReloadError is called when a reload fails.
+	onReloadError func(t *Template, err error)
 
 	buildLock sync.Mutex
 	// Build File cache; this holds a key and the old filename for the buildfile. This way,
 	// Only the first called for a set of files generates the build file in a template.
 	buildFileOldFilenameCaché map[string]string
+	// buildStamps holds the stamps of the files that went into the build file for a key, when it was last built.
+	buildStamps map[string]fileStamps
+	// buildFiles is the set of build files the template has been asked for; so they can be rebuilt on Reload.
+	buildFiles map[buildFile]struct{}
 
 	// minifiers are the list of minifiers that can be used to minify files; indexed by mimetype.
 	minifiers map[string]helpers.Minifier
@@ -117,18 +257,83 @@ func DistRoot(dist string) anOption {
 }
 
 // Helpers allow you add helper methods to the template. If the value of the map is not a function that can be accepted or the name
-// of the function is not something that can be a function name, this method will panic.
+// of the function is not something that can be a function name, this method will panic. Helpers can also be added
+// after the template is created with Template.Funcs.
 func Helpers(helpers ...template.FuncMap) anOption {
 	return func(t *Template) error {
 		for _, helper := range helpers {
 			for k, v := range helper {
 				t.helpers[k] = v
+				t.userHelpers[k] = struct{}{}
 			}
 		}
 		return nil
 	}
 }
 
+// Delims sets the action delimiters of the template to the specified strings. An empty delimiter stands for the
+// corresponding default: {{ or }}.
+func Delims(left, right string) anOption {
+	return func(t *Template) error {
+		t.leftDelim, t.rightDelim = left, right
+		return nil
+	}
+}
+
+// MissingKey controls the behavior during execution if a map is indexed with a key that is not present in the map.
+// The action is one of "default", "invalid", "zero" or "error"; as with the "missingkey" option of html/template.
+func MissingKey(action string) anOption {
+	return func(t *Template) error {
+		switch action {
+		case "default", "invalid", "zero", "error":
+		default:
+			return fmt.Errorf("Unknown missingkey action “%v”.", action)
+		}
+		t.options = append(t.options, "missingkey="+action)
+		return nil
+	}
+}
+
+// PathNames will name each template by the path of its file, relative to the resource root, without the extension;
+// so “admin/index.template” is named “admin/index”. Without this option, as with html/template, templates are named
+// by the base name of their file, and files with the same base name replace each other.
+func PathNames() anOption {
+	return func(t *Template) error {
+		t.pathNames = true
+		return nil
+	}
+}
+
+// templateName returns the name of the template the file is parsed into. With PathNames, it is an error for a file of
+// the operating system to not be under the resource root, as it can not be named by its path.
+func (t *Template) templateName(file parseFile) (string, error) {
+	if file.Name != "" {
+		return file.Name, nil
+	}
+	if !t.pathNames {
+		return baseName(t.fsys, file.File), nil
+	}
+	name := file.File
+	if isOSFS(t.fsys) {
+		// Files are opened relative to the working directory, and the resource root defaults to DefaultBase.
+		filename, err := filepath.Abs(file.File)
+		if err != nil {
+			return "", err
+		}
+		base := t.base
+		if base == "" {
+			base = DefaultBase
+		}
+		rel, ok := relName(t.fsys, base, filename)
+		if !ok {
+			return "", fmt.Errorf("template: %v is not under the resource root %v, so it can not be named by its path", file.File, base)
+		}
+		name = rel
+	}
+	name = strings.TrimPrefix(path.Clean(name), "./")
+	return strings.TrimSuffix(name, path.Ext(name)), nil
+}
+
 // ResourceRoot sets the base directory to use when resolving any resource.
 func ResourceRoot(base string) anOption {
 	return func(t *Template) error {
@@ -150,6 +355,25 @@ func URLBase(root string) anOption {
 	}
 }
 
+// ParseFS will read the templates, file lists, and globs from fsys instead of the operating system's file system. Names
+// are resolved relative to the root of fsys; ResourceRoot does not apply to it. Any patterns provided are added to the
+// files to parse as with ParseGlob.
+func ParseFS(fsys fs.FS, patterns ...string) anOption {
+	return func(t *Template) error {
+		t.fsys = fsys
+		return ParseGlob(patterns...)(t)
+	}
+}
+
+// AssetFS will read the assets for the build helpers from fsys instead of the operating system's file system. Names
+// are resolved relative to the root of fsys. The build files are still written to the DistRoot.
+func AssetFS(fsys fs.FS) anOption {
+	return func(t *Template) error {
+		t.assetFS = fsys
+		return nil
+	}
+}
+
 // Minifier to use for the given mimetype. Only one minifier is allowed per mimetype.
 func Minifier(mimetype string, minifier helpers.Minifier) anOption {
 	return func(t *Template) error {
@@ -161,23 +385,24 @@ func Minifier(mimetype string, minifier helpers.Minifier) anOption {
 	}
 }
 
-func parsePossibleGlob(base, glob string) ([]string, error) {
-	if base == "" {
+// parsePossilbleGlob will take a string that could possibly be a glob and a base. First it makes sure the glob is relative to
+// the base, then converts the glob to a set of files names. With the Strict option, a pattern that is malformed or does
+// not match any files is returned as a *PatternError; otherwise it is warned about to logger.
+func (t *Template) parsePossibleGlob(base, pattern string, logger *slog.Logger) ([]string, error) {
+	if base == "" && isOSFS(t.fsys) {
 		base = DefaultBase
 	}
-	glob = filepath.Join(base, glob)
-	matches, err := filepath.Glob(glob)
-	if err == nil && len(matches) == 0 {
-		log.Printf("WARNING: Glob(%v) did not match any files.", glob)
+	matches, err := glob(t.fsys, joinPath(t.fsys, base, pattern))
+	switch {
+	case t.strict && isBadPattern(err):
+		return nil, &PatternError{Pattern: pattern, Base: base, Err: ErrBadPattern}
+	case t.strict && err == nil && len(matches) == 0:
+		return nil, &PatternError{Pattern: pattern, Base: base, Err: ErrNoMatch}
+	case err == nil && len(matches) == 0:
+		logger.Warn("glob did not match any files", "pattern", pattern, "base", base)
 	}
 	return matches, err
 }
-
-// parsePossilbleGlob will take a string that could possibly be a glob and a base. First it makes sure the glob is relative to
-// the base, then converts the glob to a set of files names.
-func (t *Template) parsePossibleGlob(glob string) ([]string, error) {
-	return parsePossibleGlob(t.base, glob)
-}
 func (t *Template) fullLock() {
 	t.buildLock.Lock()
 	t.parseLock.Lock()
@@ -188,67 +413,12 @@ func (t *Template) fullUnlock() {
 	t.buildLock.Unlock()
 }
 
-func parseFileList(t *Template, filename string) error {
-
-	base := filepath.Dir(filename)
-	// Now we need open up the file, each line of the file will be a file path comment or empty.
-	file, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	// We need to read each line of the file, and add the line to parse file if it does not start with
-	// # or is empty.
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		txt := strings.TrimSpace(scanner.Text())
-		if len(txt) == 0 {
-			continue
-		}
-		if txt[0] == '#' {
-			continue
-		}
-
-		switch txt[0] {
-		case '.':
-			// no opt
-
-		case '/':
-
-			base, _ = os.Getwd()
-		default:
-
-			base = t.base
-		}
-
-		matches, err := parsePossibleGlob(base, txt)
-		switch err {
-		default:
-			return err
-		case filepath.ErrBadPattern:
-			t.parseFiles = append(t.parseFiles, filepath.Join(base, txt))
-		case nil:
-
-			t.parseFiles = append(t.parseFiles, matches...)
-		}
-	}
-	return nil
-}
-
 // ParseFileList will add the files from one or more file lists to the set of files to parse for the template. File
 // are reparsed in debug more for each execute statement.
 func ParseFileList(ffile string, files ...string) anOption {
 	return func(t *Template) error {
-		addSourceFile(t, SrcFileList, ffile)
-		if err := parseFileList(t, ffile); err != nil {
-			return err
-		}
-
-		for _, file := range files {
+		for _, file := range append([]string{ffile}, files...) {
 			addSourceFile(t, SrcFileList, file)
-			if err := parseFileList(t, file); err != nil {
-				return err
-			}
 		}
 		return nil
 	}
@@ -259,21 +429,57 @@ func ParseFile(files ...string) anOption {
 	return func(t *Template) error {
 		for _, file := range files {
 			addSourceFile(t, SrcParseFile, file)
-			t.parseFiles = append(t.parseFiles, file)
 		}
 		return nil
 	}
 }
 
-func parseGlob(t *Template, glob string) error {
-	matches, err := t.parsePossibleGlob(glob)
-	switch err {
+// parseGlob returns the files matched by the glob.
+func parseGlob(t *Template, glob string, logger *slog.Logger) ([]parseFile, error) {
+	src := Source{Type: SrcGlobFile, File: glob}
+	matches, err := t.parsePossibleGlob(t.baseFor(t.fsys), glob, logger)
+	switch {
+	case isBadPattern(err):
+		return parseFilesFor(src, glob), nil
+	case err != nil:
+		return nil, err
 	default:
-		return err
-	case filepath.ErrBadPattern:
-		t.parseFiles = append(t.parseFiles, glob)
-	case nil:
-		t.parseFiles = append(t.parseFiles, matches...)
+		return parseFilesFor(src, matches...), nil
+	}
+}
+
+// parseFilesInto parses the files, read from the template's file system, into tmpl. Each file is associated with a
+// template named by templateName.
+func (t *Template) parseFilesInto(tmpl *template.Template, files ...parseFile) error {
+	if len(files) == 0 {
+		return fmt.Errorf("html/template: no files named in call to ParseFiles")
+	}
+	var defs *definitions
+	if t.strictDefines {
+		defs = newDefinitions(t.leftDelim, t.rightDelim)
+	}
+	for _, file := range files {
+		b, err := fs.ReadFile(t.fsys, file.File)
+		if err != nil {
+			return &ParseError{Source: file.Source, File: file.File, Err: err}
+		}
+		name, err := t.templateName(file)
+		if err != nil {
+			return &ParseError{Source: file.Source, File: file.File, Err: err}
+		}
+		tpl := tmpl
+		if name != tmpl.Name() {
+			tpl = tmpl.New(name)
+		}
+		if _, err = tpl.Parse(string(b)); err != nil {
+			return newParseError(file, name, string(b), err)
+		}
+		if defs != nil {
+			defs.add(file.File, name, string(b))
+		}
+	}
+	if defs != nil {
+		return defs.err()
 	}
 	return nil
 }
@@ -283,9 +489,6 @@ func ParseGlob(globs ...string) anOption {
 	return func(t *Template) error {
 		for _, glob := range globs {
 			addSourceFile(t, SrcGlobFile, glob)
-			if err := parseGlob(t, glob); err != nil {
-				return err
-			}
 		}
 		return nil
 	}
@@ -295,29 +498,106 @@ func ParseGlob(globs ...string) anOption {
 func New(name string, options ...anOption) (*Template, error) {
 	t := Template{
 		name:                      name,
+		logger:                    slog.Default().With("template", name),
 		Template:                  template.New(name),
+		mode:                      defaultReloadMode,
+		liveReloadURL:             DefaultLiveReloadURL,
+		fragmentHeader:            DefaultFragmentHeader,
+		fragmentParam:             DefaultFragmentParam,
+		liveReloadInterval:        DefaultLiveReloadInterval,
+		fsys:                      helpers.OSFS{},
+		assetFS:                   helpers.OSFS{},
 		minifiers:                 make(map[string]helpers.Minifier),
 		buildFileOldFilenameCaché: make(map[string]string),
+		buildStamps:               make(map[string]fileStamps),
+		buildFiles:                make(map[buildFile]struct{}),
+		userHelpers:               make(map[string]struct{}),
 	}
 
 	// New we need to install all our Helpers. We first install our Helpers, then
 	// We install the users handlers, this does mean that the user can overwrite our
 	// Helpers
-	t.helpers = template.FuncMap{
+	t.helpers = t.defaultHelpers()
+
+	for _, opt := range options {
+		if err := opt(&t); err != nil {
+			return &t, err
+		}
+	}
+	t.Template = t.newTemplate()
+	set, err := t.newTemplateSet(t.Template)
+	if err != nil {
+		return &t, err
+	}
+	t.current.Store(&snapshot{templateSet: set})
+
+	// The files are only listed once all the options are applied, so the order of the options does not matter.
+	parseFiles, err := genParseFileList(&t, t.log())
+	if err != nil {
+		return &t, err
+	}
+	t.parseFiles = parseFiles
+	if t.mode != ReloadNever {
+		t.log().Debug("template will be reloaded", "mode", t.mode)
+	}
+	return &t, nil
+}
+
+// defaultHelpers returns the helpers every template starts out with.
+func (t *Template) defaultHelpers() template.FuncMap {
+	return template.FuncMap{
 		"buildMimeTypeFiles":  t.BuildMimeTypeFile,
 		"buildJSFiles":        t.BuildJSFile,
 		"buildLinkToJSFiles":  t.LinkToAndBuildJSFile,
 		"buildCSSFiles":       t.BuildCSSFile,
 		"buildLinkToCSSFiles": t.LinkToAndBuildCSSFile,
+		"liveReload":          t.LiveReloadScript,
+		// The context helper is replaced, for each execution, by ExecuteContext.
+		"context": context.Background,
 	}
+}
 
-	for _, opt := range options {
-		if err := opt(&t); err != nil {
-			return &t, err
-		}
+// newTemplate returns a new template, with no files parsed, that is configured with the delimiters, options and
+// helpers of the template.
+func (t *Template) newTemplate() *template.Template {
+	return template.New(t.name).
+		Delims(t.leftDelim, t.rightDelim).
+		Option(t.options...).
+		Funcs(t.helpers)
+}
+
+// Funcs adds the elements of the argument map to the helpers of the template. Unlike calling Funcs on the embedded
+// html/template, the helpers are kept when the files are reparsed. It panics if a value in the map is not a function
+// with appropriate return type.
+func (t *Template) Funcs(funcMap template.FuncMap) *Template {
+	t.fullLock()
+	defer t.fullUnlock()
+	t.Template.Funcs(funcMap)
+	for k, v := range funcMap {
+		t.helpers[k] = v
+		t.userHelpers[k] = struct{}{}
 	}
-	t.Template.Funcs(t.helpers)
-	return &t, nil
+	return t
+}
+
+// Delims sets the action delimiters to the specified strings, to be used in subsequent calls to ParseFiles. Unlike
+// calling Delims on the embedded html/template, the delimiters are kept when the files are reparsed.
+func (t *Template) Delims(left, right string) *Template {
+	t.fullLock()
+	defer t.fullUnlock()
+	t.Template.Delims(left, right)
+	t.leftDelim, t.rightDelim = left, right
+	return t
+}
+
+// Option sets options for the template, as with Option of html/template. Unlike calling Option on the embedded
+// html/template, the options are kept when the files are reparsed. It panics if an option is unknown or badly formed.
+func (t *Template) Option(opt ...string) *Template {
+	t.fullLock()
+	defer t.fullUnlock()
+	t.Template.Option(opt...)
+	t.options = append(t.options, opt...)
+	return t
 }
 
 // Must will panic if there is an error other returns the template.
@@ -0,0 +1,156 @@
+package template
+
+import (
+	"fmt"
+	"html/template"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template/parse"
+)
+
+// validate checks, with the Strict option, that a newly parsed set of templates is complete before it is published:
+// every template that is invoked with the template action has to be defined in the set.
+func validate(tmpl *template.Template) error {
+	return validateTemplates(tmpl, tmpl.Templates(), nil)
+}
+
+// validateWith is validate for a set of templates that is completed by other templates, such as the pages; provided
+// are the names of the templates those define.
+func validateWith(tmpl *template.Template, provided map[string]bool) error {
+	return validateTemplates(tmpl, tmpl.Templates(), provided)
+}
+
+// validateFrom is validate for only the templates that executing the template named root can invoke.
+func validateFrom(tmpl *template.Template, root string) error {
+	var templates []*template.Template
+	seen := make(map[string]bool)
+	queue := []string{root}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		tpl := tmpl.Lookup(name)
+		if tpl == nil || tpl.Tree == nil {
+			continue
+		}
+		templates = append(templates, tpl)
+		walkTemplateNodes(tpl.Tree.Root, func(node *parse.TemplateNode) {
+			queue = append(queue, node.Name)
+		})
+	}
+	return validateTemplates(tmpl, templates, nil)
+}
+
+// validateTemplates checks that the templates, from tmpl, only invoke templates defined in tmpl, or named in provided.
+func validateTemplates(tmpl *template.Template, templates []*template.Template, provided map[string]bool) error {
+	// Sort the templates, so that the same set of templates always reports the same error.
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name() < templates[j].Name() })
+	for _, tpl := range templates {
+		if tpl.Tree == nil || tpl.Tree.Root == nil {
+			continue
+		}
+		var err error
+		walkTemplateNodes(tpl.Tree.Root, func(node *parse.TemplateNode) {
+			if err != nil {
+				return
+			}
+			if provided[node.Name] {
+				return
+			}
+			if used := tmpl.Lookup(node.Name); used == nil || used.Tree == nil {
+				err = undefinedTemplateError(tpl, node)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkTemplateNodes calls fn for every template action found under node.
+func walkTemplateNodes(node parse.Node, fn func(*parse.TemplateNode)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkTemplateNodes(child, fn)
+		}
+	case *parse.IfNode:
+		walkTemplateNodes(n.List, fn)
+		walkTemplateNodes(n.ElseList, fn)
+	case *parse.RangeNode:
+		walkTemplateNodes(n.List, fn)
+		walkTemplateNodes(n.ElseList, fn)
+	case *parse.WithNode:
+		walkTemplateNodes(n.List, fn)
+		walkTemplateNodes(n.ElseList, fn)
+	case *parse.TemplateNode:
+		fn(n)
+	}
+}
+
+// walkIdentifiers calls fn for every identifier, the name of a function, used under node.
+func walkIdentifiers(node parse.Node, fn func(*parse.IdentifierNode)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkIdentifiers(child, fn)
+		}
+	case *parse.ActionNode:
+		walkIdentifiers(n.Pipe, fn)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkIdentifiers(cmd, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkIdentifiers(arg, fn)
+		}
+	case *parse.ChainNode:
+		walkIdentifiers(n.Node, fn)
+	case *parse.IfNode:
+		walkIdentifiers(n.Pipe, fn)
+		walkIdentifiers(n.List, fn)
+		walkIdentifiers(n.ElseList, fn)
+	case *parse.RangeNode:
+		walkIdentifiers(n.Pipe, fn)
+		walkIdentifiers(n.List, fn)
+		walkIdentifiers(n.ElseList, fn)
+	case *parse.WithNode:
+		walkIdentifiers(n.Pipe, fn)
+		walkIdentifiers(n.List, fn)
+		walkIdentifiers(n.ElseList, fn)
+	case *parse.TemplateNode:
+		walkIdentifiers(n.Pipe, fn)
+	case *parse.IdentifierNode:
+		fn(n)
+	}
+}
+
+// undefinedTemplateError returns the ParseError for the template action, in tpl, that invokes a template that is not
+// defined. The error only knows the name of the template the action was parsed in; see locateParseError.
+func undefinedTemplateError(tpl *template.Template, node *parse.TemplateNode) *ParseError {
+	location, _ := tpl.Tree.ErrorContext(node)
+	err := fmt.Errorf("template: %v: no such template %q", location, node.Name)
+	pe := &ParseError{Err: err, name: tpl.Tree.ParseName, msg: fmt.Sprintf("no such template %q", node.Name)}
+	// The location is the name the action was parsed in, followed by the line and column.
+	location = strings.TrimPrefix(location, tpl.Tree.ParseName+":")
+	if line, col, ok := strings.Cut(location, ":"); ok {
+		pe.Line, _ = strconv.Atoi(line)
+		pe.Col, _ = strconv.Atoi(col)
+	}
+	return pe
+}